@@ -1,10 +1,24 @@
 _git_get() {
+    # Bash splits words at the colon of alias prefixes, eg: gh:owner, so join them back
+    local i=$COMP_CWORD cur="${COMP_WORDS[COMP_CWORD]}"
+    while [[ $i -gt 0 && ( "$cur" == :* || "${COMP_WORDS[i-1]}" == ":" ) ]]; do
+        i=$((i - 1))
+        cur="${COMP_WORDS[i]}$cur"
+    done
+
     local limit=1
     [[ "${COMP_WORDS[0]}" == "git" ]] && limit=2
-    if [[ $COMP_CWORD -gt $limit ]]; then
+    if [[ $i -gt $limit ]]; then
         compopt +o default +o bashdefault 2>/dev/null
         return
     fi
-    COMPREPLY=($(git-get --complete "${COMP_WORDS[COMP_CWORD]}" 2>/dev/null))
+    COMPREPLY=($(git-get --complete "$cur" 2>/dev/null))
+
+    # Only the part of the word after the last colon is replaced
+    if [[ "$cur" == *:* && "$COMP_WORDBREAKS" == *:* ]]; then
+        local colon="${cur%"${cur##*:}"}"
+        COMPREPLY=("${COMPREPLY[@]#"$colon"}")
+    fi
+    [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *[/:] ]] && compopt -o nospace 2>/dev/null
 }
 complete -F _git_get git-get
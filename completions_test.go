@@ -37,6 +37,65 @@ printf '%s\n' "${COMPREPLY[@]}"`)
 	}
 }
 
+func TestBashCompletionAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("bash completion not supported on Windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not on PATH")
+	}
+	if err := gitConfigGlobalFixture(t); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if out, err := exec.Command("git", "config", "--global", "get.alias.gh", "github.com").CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v\n%s", err, out)
+	}
+
+	bin, getpath := buildCompletionFixture(t)
+
+	// COMP_WORDS as bash splits them at the colon of the alias
+	cases := map[string]struct {
+		words string
+		want  string
+	}{
+		"alias name": {
+			words: `"git-get" "g"`,
+			want:  "gh:\ngithub.com/",
+		},
+		"after colon": {
+			words: `"git-get" "gh" ":"`,
+			want:  "arbourd/",
+		},
+		"partial path": {
+			words: `"git-get" "gh" ":" "arbourd/g"`,
+			want:  "arbourd/git-get",
+		},
+		"git get": {
+			words: `"git" "get" "gh" ":" "arb"`,
+			want:  "arbourd/",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cmd := exec.Command("bash", "-c", `source completions/git-get.bash
+COMP_WORDS=(`+c.words+`)
+COMP_CWORD=$((${#COMP_WORDS[@]} - 1))
+_git_get
+printf '%s\n' "${COMPREPLY[@]}"`)
+			cmd.Env = completionEnv(t, bin, getpath)
+
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("bash: %v", err)
+			}
+			if got := strings.TrimSpace(string(out)); got != c.want {
+				t.Fatalf("unexpected output:\n\t(GOT): %q\n\t(WNT): %q", got, c.want)
+			}
+		})
+	}
+}
+
 func TestFishCompletion(t *testing.T) {
 	if _, err := exec.LookPath("fish"); err != nil {
 		t.Skip("fish not on PATH")
@@ -59,6 +118,64 @@ complete --do-complete "git-get github.com/arbourd/"`)
 	}
 }
 
+func TestBashShellInit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("bash not supported on Windows")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not on PATH")
+	}
+	if err := gitConfigGlobalFixture(t); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	bin, _ := buildCompletionFixture(t)
+	getpath := t.TempDir()
+	seedGitRepo(t, getpath, "github.com/arbourd/git-get", "https://github.com/arbourd/git-get.git")
+	dir := filepath.Join(getpath, "github.com", "arbourd", "git-get")
+
+	cases := map[string]struct {
+		script string
+		want   string
+	}{
+		"git-get changes directory": {
+			script: "git-get github.com/arbourd/git-get >/dev/null && pwd",
+			want:   dir,
+		},
+		"git get changes directory": {
+			script: "git get github.com/arbourd/git-get >/dev/null && pwd",
+			want:   dir,
+		},
+		"print-only does not change directory": {
+			script: "cd / && git get --print-only github.com/arbourd/git-get && pwd",
+			want:   dir + "\n/",
+		},
+		"subcommands do not change directory": {
+			script: "cd / && git get list >/dev/null && pwd",
+			want:   "/",
+		},
+		"completions are forwarded": {
+			script: `COMP_WORDS=("git-get" "github.com/arbourd/"); COMP_CWORD=1; _git_get; printf '%s\n' "${COMPREPLY[@]}"`,
+			want:   "github.com/arbourd/git-get",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cmd := exec.Command("bash", "-c", `eval "$(git-get shell-init bash)"`+"\n"+c.script)
+			cmd.Env = completionEnv(t, bin, getpath)
+
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("bash: %v\n%s", err, out)
+			}
+			if got := strings.TrimSpace(string(out)); got != c.want {
+				t.Fatalf("unexpected output:\n\t(GOT): %q\n\t(WNT): %q", got, c.want)
+			}
+		})
+	}
+}
+
 func buildCompletionFixture(t *testing.T) (bin, getpath string) {
 	t.Helper()
 
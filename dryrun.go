@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arbourd/git-get/get"
+)
+
+// dryRunClone prints what cloning each remote would do
+func dryRunClone(remotes []string, override func(*get.CloneOptions), stdout, stderr io.Writer) error {
+	var failed int
+	for i, remote := range remotes {
+		plan, err := planRepository(remote, override)
+		if err != nil {
+			if len(remotes) == 1 {
+				return err
+			}
+			failed++
+			fmt.Fprintf(stderr, "error: %s: %s\n", remote, err)
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		printPlan(stdout, plan)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed", failed, len(remotes))
+	}
+	return nil
+}
+
+// planRepository returns what cloning a single remote to GETPATH would do
+func planRepository(remote string, override func(*get.CloneOptions)) (get.ClonePlan, error) {
+	url, dir, err := resolveRepository(remote)
+	if err != nil {
+		return get.ClonePlan{}, err
+	}
+
+	opts, err := get.ConfigCloneOptions(url)
+	if err != nil {
+		return get.ClonePlan{}, fmt.Errorf("reading clone options: %w", err)
+	}
+	override(&opts)
+	return get.PlanClone(url, dir, opts)
+}
+
+// printPlan prints the plan for --dry-run
+func printPlan(w io.Writer, plan get.ClonePlan) {
+	exists := "no"
+	switch {
+	case plan.Repository:
+		exists = "yes, a git repository"
+	case plan.Exists:
+		exists = "yes, not a git repository"
+	}
+
+	fmt.Fprintf(w, "url:        %s\n", plan.URL)
+	fmt.Fprintf(w, "directory:  %s\n", plan.Directory)
+	fmt.Fprintf(w, "exists:     %s\n", exists)
+	switch {
+	case plan.Exists && !plan.Repository:
+		fmt.Fprintln(w, "commands:   none, the directory is occupied")
+	case len(plan.Commands) == 0 && plan.Repository:
+		fmt.Fprintln(w, "commands:   none, checked in-process by the backend")
+	case len(plan.Commands) == 0:
+		fmt.Fprintln(w, "commands:   none, cloned in-process by the backend")
+	default:
+		fmt.Fprintln(w, "commands:")
+		for _, args := range plan.Commands {
+			fmt.Fprintf(w, "  %s\n", shellJoin(args))
+		}
+	}
+}
+
+// shellJoin joins the arguments into a command line that a POSIX shell would split back
+// into the same arguments
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote single quotes the argument if it holds anything other than letters, digits
+// and punctuation that is safe to leave unquoted
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
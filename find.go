@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const findUsage = `Usage: git-get find [options] <query>
+
+Print the directory of the repository in GETPATH that best matches the query.
+The query's characters must appear in the repository's path in order, eg: gitget
+matches github.com/arbourd/git-get. Recently used repositories rank higher.
+
+Options:
+  --all         Print every matching repository, best first
+  --short       Print paths relative to the home directory
+  --print-only  Print the directory without changing to it, when wrapped by the
+                shell-init function
+  -h, --help    Show this help message`
+
+func find(args []string, stdout io.Writer) error {
+	fs := newFlagSet("find")
+	all := fs.Bool("all", false, "")
+	short := fs.Bool("short", false, "")
+	// Handled by the shell-init function; the directory is always printed
+	fs.Bool("print-only", false, "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, findUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected one query\n\n%s", findUsage)
+	}
+	query := positional[0]
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("empty query")
+	}
+
+	results, err := get.Find(query)
+	if err != nil {
+		return fmt.Errorf("finding repositories: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no repository matches %q", query)
+	}
+	if !*all {
+		results = results[:1]
+	}
+
+	for _, r := range results {
+		p := r.Path
+		if *short {
+			p = get.ShortPath(p)
+		}
+		fmt.Fprintln(stdout, p)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const foreachUsage = `Usage: git-get foreach [options] [--] <command> [args...]
+
+Run a command in every repository in GETPATH. Each line of output is prefixed
+with the repository's name. Nested repositories are skipped.
+
+Options:
+  --filter <glob>  Only run in repositories matching the glob, eg: github.com/arbourd/*
+                   May be given multiple times
+  -j, --jobs <n>   Run in n repositories at a time (default 8)
+  -h, --help       Show this help message`
+
+func foreach(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("foreach")
+	var filters stringList
+	fs.Var(&filters, "filter", "")
+	jobs := jobsFlag(fs)
+
+	// Flags after the command belong to it, so parsing stops at the first argument
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, foreachUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		return fmt.Errorf("no command specified\n\n%s", foreachUsage)
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid jobs: %d", *jobs)
+	}
+
+	repos, err := get.List(filters...)
+	if err != nil {
+		return fmt.Errorf("listing repositories: %w", err)
+	}
+
+	var mu sync.Mutex
+	var failed int
+	parallel(*jobs, len(repos), func(i int) {
+		r := repos[i]
+		out := &prefixWriter{mu: &mu, w: stdout, prefix: r.Name + ": "}
+		errOut := &prefixWriter{mu: &mu, w: stderr, prefix: r.Name + ": "}
+
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Dir = r.Path
+		cmd.Stdout = out
+		cmd.Stderr = errOut
+		err := cmd.Run()
+		out.Flush()
+		errOut.Flush()
+
+		if err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			failed++
+			fmt.Fprintf(stderr, "failed  %s: %s\n", r.Name, err)
+		}
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed", failed, len(repos))
+	}
+	return nil
+}
+
+// prefixWriter writes each complete line to w with a prefix, holding mu so that lines from
+// concurrent writers are not interleaved. Flush writes any final line without a newline.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes the buffered partial line, if any, followed by a newline
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+}
@@ -0,0 +1,47 @@
+package get
+
+import (
+	"regexp"
+	"strings"
+)
+
+// aliasSubsection is the Git config subsection that holds host aliases, eg: get.alias.gh
+const aliasSubsection = "alias"
+
+// aliasRe matches a remote with an alias prefix, as in gh:owner/repo. The remainder may not
+// start with a slash, so that URLs with a scheme, such as https://host/repo, never match.
+var aliasRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*):([^/].*)?$`)
+
+// expandAlias replaces the alias prefix of the remote, as in gh:owner/repo, with the value of
+// get.alias.<name>, such as github.com, or git@github.com: for SCP-like SSH remotes. Remotes
+// without a configured alias are unchanged.
+func expandAlias(remote string) string {
+	m := aliasRe.FindStringSubmatch(remote)
+	if m == nil {
+		return remote
+	}
+	value, ok := aliases()[strings.ToLower(m[1])]
+	if !ok {
+		return remote
+	}
+	if strings.HasSuffix(value, ":") {
+		return value + m[2]
+	}
+	return strings.TrimSuffix(value, "/") + "/" + m[2]
+}
+
+// aliases returns the host aliases from the global Git config, keyed by lowercase name
+func aliases() map[string]string {
+	return configSubsection(aliasSubsection)
+}
+
+// aliasDir returns the directory under GETPATH that an alias value expands to with the
+// default layout, eg: github.com/arbourd for https://github.com/arbourd, or an empty string
+// if it is not a URL
+func aliasDir(value string) string {
+	u, err := parseURL(strings.TrimSuffix(value, ":"))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.Trim(u.Host+"/"+strings.Trim(u.Path, "/"), "/")
+}
@@ -0,0 +1,113 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/remote"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// Backend performs the git operations Clone needs to check for, probe and clone a repository,
+// and to check and switch the ref of an existing one. The object cache always uses the git
+// command.
+type Backend interface {
+	// Probe checks that the remote repository exists and can be read, returning an error
+	// that matches ErrRemoteNotFound or ErrAuthFailed otherwise
+	Probe(ctx context.Context, u *url.URL) error
+	// Clone clones the remote repository into the empty directory dir and checks out its ref
+	Clone(ctx context.Context, u *url.URL, dir string, opts CloneOptions) error
+	// IsRepository reports whether dir holds a git repository
+	IsRepository(dir string) bool
+	// Remotes returns the URL of each remote of the repository at dir, keyed by remote name
+	Remotes(dir string) (map[string]string, error)
+	// AddRemote adds the URL as a remote with the name to the repository at dir
+	AddRemote(dir, name string, u *url.URL) error
+	// SwitchRef fetches from origin and checks out ref in the repository at dir,
+	// fast-forwarding a branch to its upstream
+	SwitchRef(ctx context.Context, dir, ref string, depth int) error
+}
+
+// backends are the Backends that can be selected with get.backend, by name
+var backends = map[string]Backend{
+	"exec":   ExecBackend{},
+	"go-git": GoGitBackend{},
+}
+
+// BackendByName returns the Backend with the name used by get.backend: exec or go-git
+func BackendByName(name string) (Backend, error) {
+	if b, ok := backends[name]; ok {
+		return b, nil
+	}
+
+	names := slices.Sorted(maps.Keys(backends))
+	return nil, fmt.Errorf("unknown backend %q, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// ExecBackend runs the git command. It is the default Backend, and supports every CloneOptions.
+type ExecBackend struct{}
+
+// Probe runs git ls-remote
+func (ExecBackend) Probe(ctx context.Context, u *url.URL) error {
+	out, err := git.RawWithContext(ctx, "ls-remote", lsRemoteArgs(u)...)
+	if ctx.Err() != nil {
+		return fmt.Errorf("git ls-remote interrupted: %w", context.Cause(ctx))
+	}
+	if err != nil {
+		return remoteError(u, "git ls-remote", out, err)
+	}
+	return nil
+}
+
+// Clone runs git clone, then checks out the ref if it is a commit
+func (ExecBackend) Clone(ctx context.Context, u *url.URL, dir string, opts CloneOptions) error {
+	if out, err := git.CloneWithContext(ctx, opts.cloneArgs(u, dir)...); err != nil {
+		if isAuthFailure(out) {
+			return fmt.Errorf("%w: %w", ErrAuthFailed, gitError("git clone", out, err))
+		}
+		return gitError("git clone", out, err)
+	}
+
+	if ref := u.Fragment; ref != "" && isCommitHash(ref) {
+		return checkoutRef(ctx, dir, ref, opts.Depth)
+	}
+	return nil
+}
+
+// lsRemoteArgs returns the options of the git ls-remote that probes the URL
+func lsRemoteArgs(u *url.URL) []types.Option {
+	return []types.Option{func(g *types.Cmd) {
+		g.AddOptions(remoteURL(u))
+	}}
+}
+
+// IsRepository reports whether dir has a .git directory or file
+func (ExecBackend) IsRepository(dir string) bool {
+	return isGitRepository(dir)
+}
+
+// Remotes runs git config
+func (ExecBackend) Remotes(dir string) (map[string]string, error) {
+	return remoteURLs(dir)
+}
+
+// AddRemote runs git remote add
+func (ExecBackend) AddRemote(dir, name string, u *url.URL) error {
+	out, err := git.Remote(global.UpperC(dir), remote.Add(name, remoteURL(u)))
+	if err != nil {
+		return gitError("git remote add", out, err)
+	}
+	return nil
+}
+
+// SwitchRef runs git fetch and git checkout, then git merge --ff-only for a branch with
+// an upstream
+func (ExecBackend) SwitchRef(ctx context.Context, dir, ref string, depth int) error {
+	return switchRef(ctx, dir, ref, depth)
+}
@@ -0,0 +1,238 @@
+package get
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackendByName(t *testing.T) {
+	cases := map[string]struct {
+		name    string
+		want    Backend
+		wantErr bool
+	}{
+		"exec": {
+			name: "exec",
+			want: ExecBackend{},
+		},
+		"go-git": {
+			name: "go-git",
+			want: GoGitBackend{},
+		},
+		"unknown": {
+			name:    "libgit2",
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b, err := BackendByName(c.name)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): nil\n")
+			} else if b != c.want {
+				t.Fatalf("unexpected backend:\n\t(GOT): %#v\n\t(WNT): %#v", b, c.want)
+			}
+		})
+	}
+}
+
+func TestCloneGoGit(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, first := fixtureRepo(t)
+	missing := *remote
+	missing.Path += "-missing"
+
+	cases := map[string]struct {
+		remote     *url.URL
+		ref        string
+		opts       CloneOptions
+		wantHead   string
+		wantBranch string
+		wantErr    error
+	}{
+		"default branch": {
+			remote:     remote,
+			wantBranch: "main",
+		},
+		"tag": {
+			remote:   remote,
+			ref:      "v1",
+			wantHead: first,
+		},
+		"branch": {
+			remote:     remote,
+			ref:        "feature",
+			wantBranch: "feature",
+		},
+		"commit": {
+			remote:   remote,
+			ref:      first[:7],
+			wantHead: first,
+		},
+		"missing remote": {
+			remote:  &missing,
+			wantErr: ErrRemoteNotFound,
+		},
+		"unsupported filter": {
+			remote:  remote,
+			opts:    CloneOptions{Filter: "blob:none"},
+			wantErr: errors.ErrUnsupported,
+		},
+		"unsupported cache": {
+			remote:  remote,
+			opts:    CloneOptions{Cache: true},
+			wantErr: errors.ErrUnsupported,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+
+			u := *c.remote
+			u.Fragment = c.ref
+			c.opts.Backend = GoGitBackend{}
+			_, err := Clone(&u, dir, c.opts)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, c.wantErr)
+				}
+				if isGitRepository(dir) {
+					t.Fatalf("expected %s not to be cloned", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+
+			if !(GoGitBackend{}).IsRepository(dir) {
+				t.Fatalf("expected %s to be a git repository", dir)
+			}
+			if c.wantHead != "" {
+				if head := gitCmd(t, dir, "rev-parse", "HEAD"); head != c.wantHead {
+					t.Fatalf("unexpected HEAD:\n\t(GOT): %s\n\t(WNT): %s", head, c.wantHead)
+				}
+			}
+			if c.wantBranch != "" {
+				if branch := gitCmd(t, dir, "branch", "--show-current"); branch != c.wantBranch {
+					t.Fatalf("unexpected branch:\n\t(GOT): %s\n\t(WNT): %s", branch, c.wantBranch)
+				}
+			}
+		})
+	}
+}
+
+func TestExistingGoGit(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, first := fixtureRepo(t)
+	fork, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		remote *url.URL
+		ref    string
+		// withoutGit removes git from PATH; go-git runs git-upload-pack to fetch file remotes
+		withoutGit bool
+		behind     bool
+		addRemote  string
+		wantHead   string
+		wantBranch string
+		wantErr    error
+	}{
+		"same remote without git": {
+			remote:     remote,
+			withoutGit: true,
+		},
+		"different remote without git": {
+			remote:     fork,
+			withoutGit: true,
+			wantErr:    ErrDestinationOccupied,
+		},
+		"different remote added without git": {
+			remote:     fork,
+			withoutGit: true,
+			addRemote:  "fork",
+		},
+		"tag": {
+			remote:   remote,
+			ref:      "v1",
+			wantHead: first,
+		},
+		"branch of origin": {
+			remote:     remote,
+			ref:        "feature",
+			wantBranch: "feature",
+		},
+		"branch moved upstream": {
+			remote:     remote,
+			ref:        "main",
+			behind:     true,
+			wantBranch: "main",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+			if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			wantHead := c.wantHead
+			if c.behind {
+				gitCmd(t, dir, "reset", "--quiet", "--hard", "HEAD~1")
+				wantHead = gitCmd(t, dir, "rev-parse", "origin/main")
+			}
+			if c.withoutGit {
+				t.Setenv("PATH", t.TempDir())
+			}
+
+			u := *c.remote
+			u.Fragment = c.ref
+			result, err := Clone(&u, dir, CloneOptions{Backend: GoGitBackend{}, AddRemote: c.addRemote})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+			if !result.Existing {
+				t.Fatalf("expected %s to be used as an existing repository", dir)
+			}
+
+			if c.addRemote != "" {
+				remotes, err := GoGitBackend{}.Remotes(dir)
+				if err != nil || remotes[c.addRemote] != fork.String() {
+					t.Fatalf("unexpected remotes:\n\t(GOT): %v %v\n\t(WNT): %s %s", remotes, err, c.addRemote, fork)
+				}
+			}
+			if wantHead != "" {
+				if head := gitCmd(t, dir, "rev-parse", "HEAD"); head != wantHead {
+					t.Fatalf("unexpected HEAD:\n\t(GOT): %s\n\t(WNT): %s", head, wantHead)
+				}
+			}
+			if c.wantBranch != "" {
+				if branch := gitCmd(t, dir, "branch", "--show-current"); branch != c.wantBranch {
+					t.Fatalf("unexpected branch:\n\t(GOT): %s\n\t(WNT): %s", branch, c.wantBranch)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/clone"
+	"github.com/ldez/go-git-cmd-wrapper/v2/fetch"
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// cacheDir is the directory under each GETPATH holding the object cache. It starts with a
+// dot so that Walk and Complete skip it.
+const cacheDir = ".cache/objects"
+
+// cachePath returns the directory of the bare mirror of the URL in the object cache of
+// the GETPATH root, eg: <root>/.cache/objects/github.com/arbourd/git-get.git
+func cachePath(root string, u *url.URL) (string, error) {
+	p, err := repositoryPath(u)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, filepath.FromSlash(cacheDir), filepath.FromSlash(p)+".git"), nil
+}
+
+// refreshCache fetches the bare mirror of the URL at mirror, creating it if it does not exist
+func refreshCache(ctx context.Context, u *url.URL, mirror string) error {
+	if _, err := os.Stat(filepath.Join(mirror, "HEAD")); err == nil {
+		out, err := git.FetchWithContext(ctx, mirrorFetchArgs(mirror)...)
+		if err != nil {
+			return gitError("git fetch", out, err)
+		}
+		return nil
+	}
+
+	// As with Clone, mirror into a temporary directory so an interrupted mirror is not reused
+	parentdir := filepath.Dir(mirror)
+	if err := os.MkdirAll(parentdir, 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parentdir, "."+filepath.Base(mirror)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	out, err := git.CloneWithContext(ctx, mirrorArgs(u, tmp)...)
+	if err == nil {
+		err = os.Rename(tmp, mirror)
+	} else {
+		err = gitError("git clone", out, err)
+	}
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	return nil
+}
+
+// mirrorArgs returns the options of the git clone that creates the mirror of the URL in dir
+func mirrorArgs(u *url.URL, dir string) []types.Option {
+	return []types.Option{clone.Mirror, clone.Quiet, clone.Repository(remoteURL(u)), clone.Directory(dir)}
+}
+
+// mirrorFetchArgs returns the options of the git fetch that refreshes the mirror
+func mirrorFetchArgs(mirror string) []types.Option {
+	return []types.Option{global.UpperC(mirror), fetch.Quiet, fetch.Prune}
+}
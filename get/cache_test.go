@@ -0,0 +1,81 @@
+package get
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCloneCache(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		opts           CloneOptions
+		wantMirror     bool
+		wantAlternates bool
+	}{
+		"without cache": {
+			opts: CloneOptions{},
+		},
+		"cache": {
+			opts:           CloneOptions{Cache: true},
+			wantMirror:     true,
+			wantAlternates: true,
+		},
+		"cache dissociated": {
+			opts:       CloneOptions{Cache: true, Dissociate: true},
+			wantMirror: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			mirror, err := cachePath(getpath, remote)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Clone twice so the mirror is both created and refreshed
+			for _, name := range []string{"first", "second"} {
+				dir := filepath.Join(getpath, "local", name)
+				if _, err := Clone(remote, dir, c.opts); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				alternates, err := os.ReadFile(filepath.Join(dir, ".git", "objects", "info", "alternates"))
+				if c.wantAlternates && (err != nil || !strings.Contains(string(alternates), mirror)) {
+					t.Fatalf("expected objects to be borrowed from %s, got: %q %v", mirror, alternates, err)
+				}
+				if !c.wantAlternates && !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("expected no alternates, got: %q %v", alternates, err)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(mirror, "HEAD")); c.wantMirror != (err == nil) {
+				t.Fatalf("unexpected mirror at %s: %v", mirror, err)
+			}
+
+			repos, err := List()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, r := range repos {
+				names = append(names, r.Name)
+			}
+			if want := []string{"local/first", "local/second"}; !slices.Equal(names, want) {
+				t.Fatalf("unexpected repositories:\n\t(GOT): %v\n\t(WNT): %v", names, want)
+			}
+		})
+	}
+}
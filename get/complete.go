@@ -5,12 +5,49 @@ import (
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // Complete returns repository paths relative to GETPATH that match the given prefix,
-// completing one path segment at a time.
+// completing one path segment at a time. Completions follow the directories on disk, so
+// they match whichever layout the repositories were cloned with and can be passed back to
+// git-get, which falls back to Lookup for paths that do not parse to themselves.
+// Host aliases defined with get.alias.<name> are completed as "<name>:", and a prefix
+// starting with one completes the paths under the host it expands to, keeping the alias.
 func Complete(prefix string) ([]string, error) {
+	aliases := aliases()
+	if m := aliasRe.FindStringSubmatch(prefix); m != nil {
+		name := strings.ToLower(m[1])
+		if dir := aliasDir(aliases[name]); dir != "" {
+			matches, err := completePaths(dir + "/" + m[2])
+			if err != nil {
+				return nil, err
+			}
+			for i, match := range matches {
+				matches[i] = m[1] + ":" + match[len(dir)+1:]
+			}
+			return matches, nil
+		}
+	}
+
+	matches, err := completePaths(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(prefix, "/:") {
+		for name := range aliases {
+			if strings.HasPrefix(name, strings.ToLower(prefix)) {
+				matches = append(matches, name+":")
+			}
+		}
+		slices.Sort(matches)
+	}
+	return matches, nil
+}
+
+// completePaths returns the repository paths in every GETPATH that match the prefix
+func completePaths(prefix string) ([]string, error) {
 	trailingSlash := strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, string(filepath.Separator))
 	if prefix != "" {
 		prefix = strings.ToLower(filepath.ToSlash(filepath.Clean(prefix)))
@@ -20,13 +57,30 @@ func Complete(prefix string) ([]string, error) {
 		prefixDepth++
 	}
 
-	getpath, err := AbsolutePath()
+	paths, err := roots()
 	if err != nil {
 		return nil, fmt.Errorf("resolving GETPATH: %w", err)
 	}
 
 	var matches []string
-	err = filepath.WalkDir(getpath, func(path string, d fs.DirEntry, err error) error {
+	for _, getpath := range paths {
+		m, err := completeIn(getpath, prefix, prefixDepth)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m...)
+	}
+	if len(paths) > 1 {
+		slices.Sort(matches)
+		matches = slices.Compact(matches)
+	}
+	return matches, nil
+}
+
+// completeIn returns the completions for prefix from the single GETPATH getpath
+func completeIn(getpath, prefix string, prefixDepth int) ([]string, error) {
+	var matches []string
+	err := filepath.WalkDir(getpath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			if path == getpath {
 				return err
@@ -104,6 +104,138 @@ func TestComplete(t *testing.T) {
 		}
 	})
 
+	t.Run("flat layout", func(t *testing.T) {
+		flatpath := t.TempDir()
+		for _, r := range []string{"git-get", "linux", "github.com/arbourd/homebrew-tap"} {
+			dir := filepath.Join(flatpath, filepath.FromSlash(r))
+			if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+		t.Setenv("GETPATH", flatpath)
+
+		got, err := Complete("gi")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"git-get", "github.com/"}; !slices.Equal(got, want) {
+			t.Fatalf("unexpected completions:\n\t(GOT): %v\n\t(WNT): %v", got, want)
+		}
+	})
+
+	t.Run("per-host roots", func(t *testing.T) {
+		srcpath := t.TempDir()
+		workpath := t.TempDir()
+		for _, r := range []string{
+			filepath.Join(srcpath, "github.com/arbourd/git-get"),
+			filepath.Join(workpath, "git.corp.example/team/repo"),
+			filepath.Join(workpath, "github.com/arbourd/git-get"),
+		} {
+			if err := os.MkdirAll(filepath.Join(filepath.FromSlash(r), ".git"), 0755); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+		setupConfig(t, map[string]string{
+			"get.path":                  srcpath,
+			"get.git.corp.example.path": workpath,
+		})
+		t.Setenv("GETPATH", "")
+
+		got, err := Complete("git")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"git.corp.example/", "github.com/"}; !slices.Equal(got, want) {
+			t.Fatalf("unexpected completions:\n\t(GOT): %v\n\t(WNT): %v", got, want)
+		}
+	})
+
+	t.Run("GETPATH list", func(t *testing.T) {
+		srcpath := t.TempDir()
+		codepath := t.TempDir()
+		for _, r := range []string{
+			filepath.Join(srcpath, "github.com/arbourd/git-get"),
+			filepath.Join(codepath, "github.com/arbourd/homebrew-tap"),
+			filepath.Join(codepath, "gitlab.com/group/repo"),
+		} {
+			if err := os.MkdirAll(filepath.Join(filepath.FromSlash(r), ".git"), 0755); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+		}
+		t.Setenv("GETPATH", srcpath+string(os.PathListSeparator)+codepath)
+
+		got, err := Complete("")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"github.com/", "gitlab.com/"}; !slices.Equal(got, want) {
+			t.Fatalf("unexpected completions:\n\t(GOT): %v\n\t(WNT): %v", got, want)
+		}
+
+		got, err = Complete("github.com/arbourd/")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []string{"github.com/arbourd/git-get", "github.com/arbourd/homebrew-tap"}; !slices.Equal(got, want) {
+			t.Fatalf("unexpected completions:\n\t(GOT): %v\n\t(WNT): %v", got, want)
+		}
+	})
+
+	t.Run("host aliases", func(t *testing.T) {
+		setupConfig(t, map[string]string{
+			"get.alias.gh":  "github.com",
+			"get.alias.gl":  "https://gitlab.com",
+			"get.alias.me":  "github.com/arbourd",
+			"get.alias.ghs": "git@github.com:",
+		})
+
+		cases := map[string]struct {
+			prefix string
+			want   []string
+		}{
+			"alias names": {
+				prefix: "g",
+				want:   []string{"gh:", "ghs:", "github.com/", "gitlab.com/", "gl:"},
+			},
+			"alias": {
+				prefix: "gh:",
+				want:   []string{"gh:arbourd/", "gh:torvalds/"},
+			},
+			"alias with partial path": {
+				prefix: "GH:arbourd/g",
+				want:   []string{"GH:arbourd/git-get"},
+			},
+			"alias with scheme": {
+				prefix: "gl:",
+				want:   []string{"gl:gitlab-org/"},
+			},
+			"alias with path": {
+				prefix: "me:",
+				want:   []string{"me:git-get"},
+			},
+			"ssh alias": {
+				prefix: "ghs:tor",
+				want:   []string{"ghs:torvalds/"},
+			},
+			"unknown alias": {
+				prefix: "nope:",
+				want:   nil,
+			},
+		}
+
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				got, err := Complete(c.prefix)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !slices.Equal(got, c.want) {
+					t.Fatalf("unexpected completions:\n\t(GOT): %v\n\t(WNT): %v", got, c.want)
+				}
+			})
+		}
+	})
+
 	t.Run("non-existent GETPATH returns empty", func(t *testing.T) {
 		t.Setenv("GETPATH", filepath.Join(t.TempDir(), "nonexistent"))
 
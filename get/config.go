@@ -0,0 +1,96 @@
+package get
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/config"
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+)
+
+// hostConfig returns the git-get config value for name scoped to the URL. Scopes are Git config
+// subsections matched against the URL's host and path, such as [get "github.com"] or
+// [get "github.com/arbourd"], and the longest matching scope wins. The unscoped get.<name>
+// is used when no scope matches.
+func hostConfig(u *url.URL, name string) string {
+	unscoped, scoped := configValues(name)
+
+	p, err := repositoryPath(u)
+	if err != nil {
+		return unscoped
+	}
+
+	var best string
+	value, found := unscoped, false
+	for scope, v := range scoped {
+		if matchesScope(scope, p) && (!found || len(scope) > len(best)) {
+			best, value, found = scope, v, true
+		}
+	}
+	return value
+}
+
+// matchesScope reports whether the scope is the repository path or one of its parents
+func matchesScope(scope, repoPath string) bool {
+	scope = strings.ToLower(strings.Trim(scope, "/"))
+	repoPath = strings.ToLower(repoPath)
+	return repoPath == scope || strings.HasPrefix(repoPath, scope+"/")
+}
+
+// configValues returns the unscoped get.<name> value and every scoped get.<scope>.<name>
+// value, keyed by scope, from the global Git config
+func configValues(name string) (string, map[string]string) {
+	// Git lowercases section and variable names, but not subsections, in its output
+	name = strings.ToLower(name)
+	pattern := `^` + gitConfigSection + `\.(.+\.)?` + regexp.QuoteMeta(name) + `$`
+
+	var unscoped string
+	scoped := make(map[string]string)
+	out, err := git.Config(config.Global, config.GetRegexp(pattern, ""))
+	if err != nil {
+		return "", scoped
+	}
+
+	for line := range strings.Lines(out) {
+		key, value, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		if key == gitConfigSection+"."+name {
+			unscoped = value
+			continue
+		}
+		scope := strings.TrimSuffix(strings.TrimPrefix(key, gitConfigSection+"."), "."+name)
+		if scope == aliasSubsection {
+			// get.alias.<name> defines an alias, not a setting scoped to a host named alias
+			continue
+		}
+		scoped[scope] = value
+	}
+	return unscoped, scoped
+}
+
+// configSubsection returns every get.<subsection>.<name> value from the global Git config,
+// keyed by name, which Git lowercases
+func configSubsection(subsection string) map[string]string {
+	values := make(map[string]string)
+	pattern := `^` + gitConfigSection + `\.` + regexp.QuoteMeta(subsection) + `\.[^.]+$`
+	out, err := git.Config(config.Global, config.GetRegexp(pattern, ""))
+	if err != nil {
+		return values
+	}
+
+	prefix := gitConfigSection + "." + subsection + "."
+	for line := range strings.Lines(out) {
+		key, value, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		values[strings.TrimPrefix(key, prefix)] = value
+	}
+	return values
+}
+
+// gitConfig returns the value of key in the global Git config, or an empty string if it is unset
+func gitConfig(key string) string {
+	out, err := git.Config(config.Global, config.Get(key, ""))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
@@ -0,0 +1,58 @@
+package get
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultHostKey is the Git config key for the host of remotes given without one
+	DefaultHostKey = "get.defaultHost"
+	// DefaultOwnerKey is the Git config key for the owner of remotes given as a bare repository name
+	DefaultOwnerKey = "get.defaultOwner"
+)
+
+// expandDefaults fills in the default host and owner for a remote that does not start with a
+// host. Remotes with a scheme, or that start with /, . or ~, are never changed. Otherwise:
+//
+//   - repo, a single segment without a colon, becomes <get.defaultHost>/<get.defaultOwner>/repo,
+//     even if it has a dot, such as socket.io
+//   - host/owner/repo is unchanged when the first segment has a dot or a colon, or is localhost
+//   - owner/repo, or group/subgroup/repo, becomes <get.defaultHost>/owner/repo
+//
+// An error is returned if a setting that is needed is not set, rather than guessing.
+func expandDefaults(remote string) (string, error) {
+	if !hostless(remote) {
+		return remote, nil
+	}
+
+	_, _, hasOwner := strings.Cut(remote, "/")
+	host := strings.TrimSuffix(gitConfig(DefaultHostKey), "/")
+	if host == "" {
+		return "", fmt.Errorf("no host in %q, expected eg: github.com/%s, or set %s", remote, remote, DefaultHostKey)
+	}
+	if hasOwner {
+		return host + "/" + remote, nil
+	}
+
+	owner := strings.Trim(gitConfig(DefaultOwnerKey), "/")
+	if owner == "" {
+		return "", fmt.Errorf("no owner in %q, expected eg: owner/%s, or set %s", remote, remote, DefaultOwnerKey)
+	}
+	return host + "/" + owner + "/" + remote, nil
+}
+
+// HasDefaultHost reports whether the remote, after expanding any host alias, does not start
+// with a host and so is given get.defaultHost by ParseURL, eg: owner/repo or repo
+func HasDefaultHost(remote string) bool {
+	return hostless(expandAlias(remote))
+}
+
+// hostless reports whether expandDefaults fills in the host of the remote
+func hostless(remote string) bool {
+	if remote == "" || strings.Contains(remote, "://") || strings.ContainsAny(remote[:1], "/.~") {
+		return false
+	}
+	first, _, hasOwner := strings.Cut(remote, "/")
+	return !strings.Contains(first, ":") && !(hasOwner && (strings.Contains(first, ".") || strings.EqualFold(first, "localhost")))
+}
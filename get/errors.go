@@ -0,0 +1,66 @@
+package get
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// Errors returned by Clone and Remove, which callers can test for with errors.Is.
+// They are wrapped with details such as the directory or URL.
+var (
+	// ErrRemoteNotFound is returned when the remote repository does not exist or is unreachable
+	ErrRemoteNotFound = errors.New("git repository not found")
+	// ErrAuthFailed is returned when the remote rejects or cannot prompt for credentials
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrNotARepository is returned when a directory expected to hold a repository does not
+	ErrNotARepository = errors.New("not a git repository")
+	// ErrDestinationOccupied is returned when the directory a repository would be cloned to
+	// already holds something else. *RemoteMismatchError matches it.
+	ErrDestinationOccupied = errors.New("destination occupied")
+	// ErrOutsideGetpath is returned when a URL or directory would resolve outside of GETPATH.
+	// *UnsafeDirectoryError matches it.
+	ErrOutsideGetpath = errors.New("outside of GETPATH")
+)
+
+// authFailures are messages printed by git and its credential helpers and SSH when
+// authenticating with a remote fails
+var authFailures = []string{
+	"authentication failed",
+	"could not read username",
+	"could not read password",
+	"terminal prompts disabled",
+	"permission denied (publickey",
+	"host key verification failed",
+	"the requested url returned error: 401",
+	"the requested url returned error: 403",
+}
+
+// isAuthFailure reports whether the output of a git command shows an authentication failure
+func isAuthFailure(out string) bool {
+	out = strings.ToLower(out)
+	for _, msg := range authFailures {
+		if strings.Contains(out, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteError wraps the failure of a git command that contacted the remote at u with
+// ErrAuthFailed if authentication failed, or else ErrRemoteNotFound. Failures to run git,
+// such as git not being installed, are returned without either.
+func remoteError(u *url.URL, cmd, out string, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return gitError(cmd, out, err)
+	}
+
+	sentinel := ErrRemoteNotFound
+	if isAuthFailure(out) {
+		sentinel = ErrAuthFailed
+	}
+	return fmt.Errorf("%w at %s: %w", sentinel, SanitizedURL(u), gitError(cmd, out, err))
+}
@@ -0,0 +1,144 @@
+package get
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+	fork, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		run             func(t *testing.T, getpath string) error
+		want            error
+		wantErrContains string
+	}{
+		"remote not found": {
+			run: func(t *testing.T, getpath string) error {
+				missing := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(t.TempDir(), "missing"))}
+				_, err := Clone(missing, filepath.Join(getpath, "missing"), CloneOptions{})
+				return err
+			},
+			want:            ErrRemoteNotFound,
+			wantErrContains: "does not appear to be a git repository",
+		},
+		"destination occupied by a directory": {
+			run: func(t *testing.T, getpath string) error {
+				dir := filepath.Join(getpath, "repo")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				_, err := Clone(remote, dir, CloneOptions{})
+				return err
+			},
+			want: ErrDestinationOccupied,
+		},
+		"destination occupied by another repository": {
+			run: func(t *testing.T, getpath string) error {
+				dir := filepath.Join(getpath, "repo")
+				if _, err := Clone(fork, dir, CloneOptions{}); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				_, err := Clone(remote, dir, CloneOptions{})
+				return err
+			},
+			want: ErrDestinationOccupied,
+		},
+		"outside GETPATH": {
+			run: func(t *testing.T, getpath string) error {
+				_, err := Clone(remote, t.TempDir(), CloneOptions{})
+				return err
+			},
+			want: ErrOutsideGetpath,
+		},
+		"unsafe URL": {
+			run: func(t *testing.T, getpath string) error {
+				_, err := Clone(&url.URL{Scheme: "https", Host: "evil.com", Path: "/../../.ssh"}, filepath.Join(getpath, "ssh"), CloneOptions{})
+				return err
+			},
+			want: ErrOutsideGetpath,
+		},
+		"remove a directory that is not a repository": {
+			run: func(t *testing.T, getpath string) error {
+				dir := filepath.Join(getpath, "repo")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				return Remove(dir, RemoveOptions{})
+			},
+			want: ErrNotARepository,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+
+			err := c.run(t, getpath)
+			if !errors.Is(err, c.want) {
+				t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, c.want)
+			}
+			if c.wantErrContains != "" && !strings.Contains(err.Error(), c.wantErrContains) {
+				t.Fatalf("unexpected error message:\n\t(GOT): %v\n\t(WNT): contains %q", err, c.wantErrContains)
+			}
+		})
+	}
+}
+
+func TestProbeWithoutGit(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	u := &url.URL{Scheme: "https", Host: "github.com", Path: "arbourd/git-get"}
+	err := ExecBackend{}.Probe(context.Background(), u)
+	if !errors.Is(err, exec.ErrNotFound) || errors.Is(err, ErrRemoteNotFound) {
+		t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, exec.ErrNotFound)
+	}
+}
+
+func TestIsAuthFailure(t *testing.T) {
+	cases := map[string]struct {
+		out  string
+		want bool
+	}{
+		"https prompt": {
+			out:  "fatal: could not read Username for 'https://github.com': terminal prompts disabled",
+			want: true,
+		},
+		"https rejected": {
+			out:  "remote: Invalid username or token.\nfatal: Authentication failed for 'https://github.com/arbourd/private.git/'",
+			want: true,
+		},
+		"ssh key": {
+			out:  "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.",
+			want: true,
+		},
+		"not found": {
+			out:  "remote: Repository not found.\nfatal: repository 'https://github.com/arbourd/missing/' not found",
+			want: false,
+		},
+		"network": {
+			out:  "fatal: unable to access 'https://example.invalid/': Could not resolve host: example.invalid",
+			want: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := isAuthFailure(c.out); got != c.want {
+				t.Fatalf("unexpected result:\n\t(GOT): %v\n\t(WNT): %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package get
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Match scores used by Find. A matched character scores more when it starts a path segment
+// or word, or follows the previous match, and less the further it is from the previous match.
+const (
+	scoreMatch       = 1
+	scoreBoundary    = 8
+	scoreConsecutive = 4
+	scoreGap         = -1
+	// scoreRepo is added when the whole query matches within the last path segment
+	scoreRepo = 10
+	// scoreExact is added when the query is the last path segment
+	scoreExact = 20
+)
+
+// recencyScores are added to the score of repositories used within each duration
+var recencyScores = []struct {
+	within time.Duration
+	score  int
+}{
+	{24 * time.Hour, 8},
+	{7 * 24 * time.Hour, 4},
+	{30 * 24 * time.Hour, 2},
+}
+
+// FindResult is a repository matched by Find
+type FindResult struct {
+	Repository
+	// Score ranks the result against the others; higher is better
+	Score int `json:"score"`
+	// LastUsed is when the repository's Git metadata was last modified
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Find returns the repositories under GETPATH whose name fuzzy matches the query, best first.
+// The query's characters must appear in the name in order, case-insensitively, so "gitget"
+// matches "github.com/arbourd/git-get". Results are ranked by how closely they match, with
+// matches in the repository name and at the start of words ranking higher, and then by how
+// recently they were used. Every repository matches an empty query.
+func Find(query string) ([]FindResult, error) {
+	repos, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var results []FindResult
+	for _, r := range repos {
+		score, ok := fuzzyScore(query, r.Name)
+		if !ok {
+			continue
+		}
+		used := lastUsed(r.Path)
+		for _, rs := range recencyScores {
+			if now.Sub(used) < rs.within {
+				score += rs.score
+				break
+			}
+		}
+		results = append(results, FindResult{Repository: r, Score: score, LastUsed: used})
+	}
+
+	slices.SortStableFunc(results, func(a, b FindResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			b.LastUsed.Compare(a.LastUsed),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return results, nil
+}
+
+// fuzzyScore reports whether every character of query appears in name in order, ignoring
+// case, and scores the best such match
+func fuzzyScore(query, name string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	n := []rune(strings.ToLower(name))
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	repoStart := 0
+	for i, r := range n {
+		if r == '/' {
+			repoStart = i + 1
+		}
+	}
+
+	best, found := 0, false
+	for start := range n {
+		if n[start] != q[0] {
+			continue
+		}
+		score, ok := scoreFrom(q, n, start, repoStart)
+		if ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	if string(n[repoStart:]) == string(q) {
+		best += scoreExact
+	}
+	return best, true
+}
+
+// scoreFrom greedily matches q in n beginning at start and returns the match's score
+func scoreFrom(q, n []rune, start, repoStart int) (int, bool) {
+	score, qi, prev := 0, 0, -1
+	for i := start; i < len(n) && qi < len(q); i++ {
+		if n[i] != q[qi] {
+			continue
+		}
+		score += scoreMatch
+		if i == 0 || strings.ContainsRune("/-_. ", n[i-1]) {
+			score += scoreBoundary
+		}
+		if prev >= 0 {
+			if i == prev+1 {
+				score += scoreConsecutive
+			} else {
+				score += scoreGap * (i - prev - 1)
+			}
+		}
+		prev = i
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	if start >= repoStart {
+		score += scoreRepo
+	}
+	return score, true
+}
+
+// lastUsed returns when the Git metadata of the repository at dir was last modified, such
+// as by a commit, checkout, fetch or status
+func lastUsed(dir string) time.Time {
+	var last time.Time
+	for _, name := range []string{"HEAD", "index", "FETCH_HEAD", filepath.Join("logs", "HEAD")} {
+		fi, err := os.Stat(filepath.Join(dir, ".git", name))
+		if err == nil && fi.ModTime().After(last) {
+			last = fi.ModTime()
+		}
+	}
+	return last
+}
@@ -0,0 +1,106 @@
+package get
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	getpath := t.TempDir()
+	repos := []string{
+		"github.com/arbourd/git-get",
+		"github.com/arbourd/git-get-action",
+		"github.com/arbourd/homebrew-tap",
+		"github.com/golang/go",
+		"github.com/torvalds/linux",
+		"gitlab.com/gitlab-org/gitlab",
+	}
+	old := time.Now().Add(-365 * 24 * time.Hour)
+	for _, r := range repos {
+		dir := filepath.Join(getpath, filepath.FromSlash(r), ".git")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		if err := os.Chtimes(filepath.Join(dir, "HEAD"), old, old); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	t.Setenv("GETPATH", getpath)
+
+	cases := map[string]struct {
+		query string
+		want  []string
+	}{
+		"across segments": {
+			query: "gitget",
+			want:  []string{"github.com/arbourd/git-get", "github.com/arbourd/git-get-action"},
+		},
+		"case insensitive": {
+			query: "TAP",
+			want:  []string{"github.com/arbourd/homebrew-tap"},
+		},
+		"repository name ranks first": {
+			query: "gitlab",
+			want:  []string{"gitlab.com/gitlab-org/gitlab"},
+		},
+		"owner matches every repository": {
+			query: "arbourd",
+			want:  []string{"github.com/arbourd/git-get", "github.com/arbourd/git-get-action", "github.com/arbourd/homebrew-tap"},
+		},
+		"exact repository name ranks above partial": {
+			query: "git-get-action",
+			want:  []string{"github.com/arbourd/git-get-action"},
+		},
+		"word starts rank above scattered matches": {
+			query: "gga",
+			want:  []string{"github.com/arbourd/git-get-action", "github.com/golang/go", "gitlab.com/gitlab-org/gitlab"},
+		},
+		"no match": {
+			query: "zzz",
+			want:  nil,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			results, err := Find(c.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Name)
+			}
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("unexpected results:\n\t(GOT): %v\n\t(WNT): %v", got, c.want)
+			}
+		})
+	}
+
+	t.Run("recently used ranks first", func(t *testing.T) {
+		now := time.Now()
+		head := filepath.Join(getpath, "github.com", "arbourd", "homebrew-tap", ".git", "HEAD")
+		if err := os.Chtimes(head, now, now); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+
+		results, err := Find("arbourd")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) == 0 || results[0].Name != "github.com/arbourd/homebrew-tap" {
+			t.Fatalf("unexpected results:\n\t(GOT): %v\n\t(WNT): github.com/arbourd/homebrew-tap first", results)
+		}
+	})
+}
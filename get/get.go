@@ -1,17 +1,21 @@
 package get
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/ldez/go-git-cmd-wrapper/v2/clone"
-	"github.com/ldez/go-git-cmd-wrapper/v2/config"
 	"github.com/ldez/go-git-cmd-wrapper/v2/git"
 	"github.com/ldez/go-git-cmd-wrapper/v2/types"
 )
@@ -29,20 +33,184 @@ const (
 
 	// EnvKey is the name of the environmental variable that is used to store GETPATH information
 	EnvKey = "GETPATH"
+
+	// gitConfigSection is the Git config section that holds all git-get settings
+	gitConfigSection = "get"
 )
 
+// CloneOptions configures how a repository is cloned
+type CloneOptions struct {
+	// Depth truncates the history to the given number of commits when greater than zero
+	Depth int
+	// ShallowSince truncates the history to commits newer than the given date
+	ShallowSince string
+	// Filter requests a partial clone using the given object filter, eg: blob:none or tree:0
+	Filter string
+	// AddRemote is the name of a remote to add to an existing repository whose remotes do not
+	// match the URL. When empty, Clone returns a *RemoteMismatchError instead.
+	AddRemote string
+	// Cache clones with objects borrowed from a bare mirror of the repository kept under
+	// GETPATH/.cache/objects, which is created or fetched first
+	Cache bool
+	// Dissociate copies the objects borrowed from the cache into the clone, so that it does
+	// not depend on the cache afterwards
+	Dissociate bool
+	// Backend probes and clones the repository. When nil, ExecBackend is used.
+	Backend Backend
+
+	// reference is the cache mirror to borrow objects from, set by Clone
+	reference string
+}
+
+// ConfigCloneOptions returns the default CloneOptions for the URL from the global Git config.
+// Scoped keys (get.<host>.depth) take precedence over the section-wide keys (get.depth).
+func ConfigCloneOptions(u *url.URL) (CloneOptions, error) {
+	var opts CloneOptions
+
+	if v := hostConfig(u, "depth"); v != "" {
+		depth, err := strconv.Atoi(v)
+		if err != nil || depth < 0 {
+			return CloneOptions{}, fmt.Errorf("invalid depth in git config: %q", v)
+		}
+		opts.Depth = depth
+	}
+	opts.ShallowSince = hostConfig(u, "shallowSince")
+	opts.Filter = hostConfig(u, "filter")
+
+	for name, b := range map[string]*bool{"cache": &opts.Cache, "dissociate": &opts.Dissociate} {
+		v := hostConfig(u, name)
+		if v == "" {
+			continue
+		}
+		ok, err := parseBool(v)
+		if err != nil {
+			return CloneOptions{}, fmt.Errorf("invalid %s in git config: %q", name, v)
+		}
+		*b = ok
+	}
+
+	if v := hostConfig(u, "backend"); v != "" {
+		b, err := BackendByName(v)
+		if err != nil {
+			return CloneOptions{}, fmt.Errorf("invalid backend in git config: %q", v)
+		}
+		opts.Backend = b
+	}
+	return opts, nil
+}
+
+// backend returns the Backend to clone with
+func (o CloneOptions) backend() Backend {
+	if o.Backend == nil {
+		return ExecBackend{}
+	}
+	return o.Backend
+}
+
+// parseBool parses a Git config boolean, such as true, yes, on or 1
+func parseBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", v)
+}
+
 // AbsolutePath returns the absolute GETPATH, resolving env vars and ~ expansion.
+// GETPATH may be a list separated by os.PathListSeparator, like GOPATH, in which case the
+// first entry, where repositories are cloned to, is returned.
 // Precedence: GETPATH env var > get.path git config > default.
 func AbsolutePath() (string, error) {
+	paths, err := AbsolutePaths()
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+// AbsolutePaths returns every entry of the GETPATH list, in order.
+// Precedence: GETPATH env var > get.path git config > default.
+func AbsolutePaths() ([]string, error) {
 	p := os.Getenv(EnvKey)
 	if p == "" {
-		out, _ := git.Config(config.Global, config.Get(GitConfigKey, ""))
-		p = strings.TrimSpace(out)
+		p = gitConfig(GitConfigKey)
 	}
+	return expandGetpaths(p)
+}
+
+// AbsolutePathFor returns the absolute GETPATH that the URL is cloned to.
+// Precedence: GETPATH env var > get.<scope>.path git config for the longest scope matching
+// the URL's host and path, eg: get.github.com/arbourd.path > get.path git config > default.
+// As with AbsolutePath, the first entry of a list is returned.
+func AbsolutePathFor(u *url.URL) (string, error) {
+	p := os.Getenv(EnvKey)
 	if p == "" {
-		p = defaultGetpath
+		p = hostConfig(u, "path")
+	}
+	paths, err := expandGetpaths(p)
+	if err != nil {
+		return "", err
 	}
+	return paths[0], nil
+}
 
+// roots returns every GETPATH that repositories may be found in: the entries of the default
+// GETPATH followed by those of each scoped get.<scope>.path, unless the GETPATH env var
+// overrides them all
+func roots() ([]string, error) {
+	paths, err := AbsolutePaths()
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv(EnvKey) != "" {
+		return paths, nil
+	}
+
+	_, scoped := configValues("path")
+	for _, scope := range slices.Sorted(maps.Keys(scoped)) {
+		scopedPaths, err := expandGetpaths(scoped[scope])
+		if err != nil {
+			return nil, fmt.Errorf("get.%s.path: %w", scope, err)
+		}
+		for _, p := range scopedPaths {
+			if !slices.Contains(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// expandGetpaths splits the GETPATH list p and expands each entry, using the default when
+// the list is empty. Empty entries are ignored.
+func expandGetpaths(p string) ([]string, error) {
+	var paths []string
+	for _, entry := range filepath.SplitList(p) {
+		if entry == "" {
+			continue
+		}
+		path, err := expandGetpath(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		path, err := expandGetpath(defaultGetpath)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// expandGetpath resolves env vars and ~ in a single GETPATH entry
+func expandGetpath(p string) (string, error) {
 	p = os.ExpandEnv(p)
 	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
 		home, err := os.UserHomeDir()
@@ -78,19 +246,36 @@ func ShortPath(path string) string {
 
 var scpSyntaxRe = regexp.MustCompile(`^(\w+)@([\w.-]+):(.*)$`)
 
-// ParseURL parses and returns a URL from the remote string provided
+// ParseURL parses and returns a URL from the remote string provided.
+// A ref to check out may follow the repository path, eg: "github.com/user/repo@v1.2.3",
+// and is returned as the URL fragment. A host alias defined with get.alias.<name> may
+// prefix the path, eg: "gh:user/repo", and is expanded first. A remote that does not start
+// with a host, eg: "user/repo" or "repo", gets get.defaultHost and get.defaultOwner.
 func ParseURL(remote string) (*url.URL, error) {
+	remote, err := expandDefaults(expandAlias(remote))
+	if err != nil {
+		return nil, err
+	}
+	return parseURL(remote)
+}
+
+// parseURL parses the remote as ParseURL does, without expanding aliases or defaults
+func parseURL(remote string) (*url.URL, error) {
 	// Parse and return URL if valid SCP
 	if m := scpSyntaxRe.FindStringSubmatch(remote); m != nil {
 		// Match SCP-like syntax and convert it to a URL.
 		// Eg, "git@github.com:user/repo" becomes
 		// "ssh://git@github.com/user/repo".
-		return &url.URL{
+		u := &url.URL{
 			Scheme: "ssh",
 			User:   url.User(m[1]),
 			Host:   m[2],
 			Path:   m[3],
-		}, nil
+		}
+		if err := splitRef(u); err != nil {
+			return nil, err
+		}
+		return u, nil
 	}
 
 	u, err := url.Parse(remote)
@@ -99,53 +284,342 @@ func ParseURL(remote string) (*url.URL, error) {
 	}
 
 	if len(u.Scheme) == 0 {
-		u.Scheme = defaultScheme
+		// Parse again with the default scheme so the host is not mistaken for part of the path
+		u, err = url.Parse(defaultScheme + "://" + remote)
+		if err != nil {
+			return nil, fmt.Errorf("parsing url: %w", err)
+		}
+	}
+	if err := splitRef(u); err != nil {
+		return nil, err
 	}
 	return u, nil
 }
 
-// Directory parses the directory where the cloned repository will be downloaded from the URL
+// UnsafeDirectoryError is returned when a URL or directory would clone outside of GETPATH
+// or into a path that is not portable
+type UnsafeDirectoryError struct {
+	Dir    string
+	Reason string
+}
+
+func (e *UnsafeDirectoryError) Error() string {
+	return fmt.Sprintf("unsafe directory %q: %s", e.Dir, e.Reason)
+}
+
+// Is reports whether target is ErrOutsideGetpath, as an unsafe directory cannot be used
+// within GETPATH
+func (e *UnsafeDirectoryError) Is(target error) bool {
+	return target == ErrOutsideGetpath
+}
+
+// Directory parses the directory where the cloned repository will be downloaded from the URL,
+// arranged by the layout configured for its host.
+// URLs with ".." segments, backslashes, control characters, names reserved on Windows or
+// no repository name return an *UnsafeDirectoryError.
 func Directory(u *url.URL) (string, error) {
-	dir, err := url.JoinPath(u.Host, u.Path)
+	p, err := repositoryPath(u)
+	if err != nil {
+		return "", err
+	}
+
+	layout, err := Layout(u)
+	if err != nil {
+		return "", err
+	}
+	dir, err := expandLayout(layout, p)
 	if err != nil {
-		return "", fmt.Errorf("joining path: %w", err)
+		return "", err
 	}
-	dir = strings.TrimSuffix(dir, ".git")
 	return filepath.Clean(dir), nil
 }
 
-// Clone clones the remote repository to the GETPATH and returns the directory
-func Clone(u *url.URL, dir string) (string, error) {
-	if isGitRepository(dir) {
-		return dir, nil
+// repositoryPath returns the host and path of the URL without the ".git" suffix, which
+// identifies the repository regardless of layout, eg: github.com/arbourd/git-get
+func repositoryPath(u *url.URL) (string, error) {
+	p := strings.TrimPrefix(u.Host+"/"+strings.TrimPrefix(u.Path, "/"), "/")
+	p = strings.TrimSuffix(strings.TrimSuffix(p, "/"), ".git")
+	if err := checkSegments(p); err != nil {
+		return "", err
+	}
+	if i := strings.LastIndex(p, "/"); i < 0 || strings.Trim(p[i+1:], ".") == "" {
+		return "", &UnsafeDirectoryError{Dir: p, Reason: "no repository name"}
+	}
+	return path.Clean(p), nil
+}
+
+// CloneResult describes the repository that Clone cloned or found
+type CloneResult struct {
+	// URL is the URL of the repository without user information or ref
+	URL string
+	// Directory is the directory of the repository
+	Directory string
+	// Existing is set when the repository was already in GETPATH and was not cloned
+	Existing bool
+}
+
+// Clone clones the remote repository to the GETPATH and describes the result. It is
+// CloneContext with a background context.
+func Clone(u *url.URL, dir string, opts CloneOptions) (CloneResult, error) {
+	return CloneContext(context.Background(), u, dir, opts)
+}
+
+// CloneContext clones the remote repository to the GETPATH and describes the result. When another
+// GETPATH entry already holds the repository at the same relative path, that directory is used.
+// If the URL has a ref, the working tree is checked out at it, fetching first when the
+// repository already exists. An *UnsafeDirectoryError is returned if the URL is unsafe
+// or dir is not within GETPATH, and a *RemoteMismatchError if dir holds a different repository.
+// If ctx is done before the clone completes, git is killed and the partial clone is removed.
+func CloneContext(ctx context.Context, u *url.URL, dir string, opts CloneOptions) (CloneResult, error) {
+	paths, dir, err := cloneDir(u, dir)
+	if err != nil {
+		return CloneResult{}, err
+	}
+
+	result := CloneResult{URL: SanitizedURL(u), Directory: dir}
+	b := opts.backend()
+	if b.IsRepository(dir) {
+		if err := useExisting(ctx, u, dir, opts); err != nil {
+			return CloneResult{}, err
+		}
+		result.Existing = true
+		return result, nil
 	}
 
 	if _, statErr := os.Stat(dir); statErr == nil {
-		return "", fmt.Errorf("%s exists but %s does not", dir, filepath.Join(dir, ".git"))
+		return CloneResult{}, fmt.Errorf("%w: %s exists but %s does not", ErrDestinationOccupied, dir, filepath.Join(dir, ".git"))
 	} else if !errors.Is(statErr, fs.ErrNotExist) {
-		return "", fmt.Errorf("checking directory: %w", statErr)
+		return CloneResult{}, fmt.Errorf("checking directory: %w", statErr)
 	}
 
 	// Check if git remote exists before creating any directories
-	_, err := git.Raw("ls-remote", func(g *types.Cmd) {
-		g.AddOptions(u.String())
-	})
+	if err := b.Probe(ctx, u); err != nil {
+		if ctx.Err() != nil {
+			return CloneResult{}, fmt.Errorf("git ls-remote interrupted: %w", context.Cause(ctx))
+		}
+		return CloneResult{}, err
+	}
+
+	if opts.Cache {
+		// The cache is a bare mirror kept up to date with git, which only git can borrow from
+		if _, ok := b.(ExecBackend); !ok {
+			return CloneResult{}, fmt.Errorf("object cache requires the exec backend: %w", errors.ErrUnsupported)
+		}
+		opts.reference, err = cachePath(rootOf(paths, dir), u)
+		if err == nil {
+			err = refreshCache(ctx, u, opts.reference)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return CloneResult{}, fmt.Errorf("git clone interrupted: %w", context.Cause(ctx))
+			}
+			return CloneResult{}, fmt.Errorf("refreshing object cache: %w", err)
+		}
+	}
+
+	// Clone into a hidden sibling directory and move it into place once complete, so that a
+	// failed or interrupted clone does not leave a partial repository behind
+	parentdir := filepath.Dir(dir)
+	created, err := mkdirAll(parentdir)
+	if err != nil {
+		return CloneResult{}, fmt.Errorf("creating clone directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(parentdir, "."+filepath.Base(dir)+".tmp-")
+	if err != nil {
+		removeEmptyDirs(parentdir, created)
+		return CloneResult{}, fmt.Errorf("creating clone directory: %w", err)
+	}
+
+	err = b.Clone(ctx, u, tmp, opts)
+	if err == nil {
+		err = os.Rename(tmp, dir)
+		if err != nil && b.IsRepository(dir) {
+			// Another clone of the same repository, such as by another process, finished first
+			_ = os.RemoveAll(tmp)
+			if err := useExisting(ctx, u, dir, opts); err != nil {
+				return CloneResult{}, err
+			}
+			result.Existing = true
+			return result, nil
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		removeEmptyDirs(parentdir, created)
+		if ctx.Err() != nil {
+			return CloneResult{}, fmt.Errorf("git clone interrupted: %w", context.Cause(ctx))
+		}
+		return CloneResult{}, err
+	}
+	return result, nil
+}
+
+// useExisting checks that the existing repository at dir has a remote matching the URL,
+// then switches it to the URL's ref
+func useExisting(ctx context.Context, u *url.URL, dir string, opts CloneOptions) error {
+	b := opts.backend()
+	if err := checkRemote(b, u, dir, opts.AddRemote); err != nil {
+		return err
+	}
+	if ref := u.Fragment; ref != "" {
+		return b.SwitchRef(ctx, dir, ref, opts.Depth)
+	}
+	return nil
+}
+
+// cloneDir checks that the URL is safe and dir is within GETPATH, and returns the GETPATH
+// roots and the directory to clone to. A repository held by any GETPATH entry already
+// exists, even if dir is in another.
+func cloneDir(u *url.URL, dir string) ([]string, string, error) {
+	if _, err := repositoryPath(u); err != nil {
+		return nil, "", err
+	}
+	paths, err := roots()
 	if err != nil {
-		sanitized := *u
-		sanitized.User = nil
-		return "", fmt.Errorf("git repository not found at %s: %w", sanitized.String(), err)
+		return nil, "", fmt.Errorf("resolving GETPATH: %w", err)
+	}
+	if !slices.ContainsFunc(paths, func(root string) bool { return within(root, dir) }) {
+		return nil, "", &UnsafeDirectoryError{Dir: dir, Reason: fmt.Sprintf("not within GETPATH %s", strings.Join(paths, string(os.PathListSeparator)))}
 	}
+	return paths, existingDir(paths, dir), nil
+}
+
+// Probe checks that the remote repository exists and can be read with git, returning an
+// error that matches ErrRemoteNotFound or ErrAuthFailed otherwise
+func Probe(u *url.URL) error {
+	return ProbeContext(context.Background(), u)
+}
+
+// ProbeContext is Probe with a context that kills git when done
+func ProbeContext(ctx context.Context, u *url.URL) error {
+	return ExecBackend{}.Probe(ctx, u)
+}
 
-	parentdir, _ := filepath.Split(dir)
-	if err := os.MkdirAll(parentdir, 0755); err != nil {
-		return "", fmt.Errorf("creating clone directory: %w", err)
+// mkdirAll creates dir along with any missing parents and returns the topmost directory
+// it created, or an empty string if dir already existed
+func mkdirAll(dir string) (string, error) {
+	var top string
+	for p := dir; ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil || filepath.Dir(p) == p {
+			break
+		}
+		top = p
 	}
+	return top, os.MkdirAll(dir, 0755)
+}
 
-	if _, err = git.Clone(clone.Repository(u.String()), clone.Directory(dir)); err != nil {
-		return "", fmt.Errorf("git clone: %w", err)
+// removeEmptyDirs removes dir and its parents up to and including top, stopping at the
+// first directory that is not empty
+func removeEmptyDirs(dir, top string) {
+	if top == "" {
+		return
 	}
+	for p := dir; ; p = filepath.Dir(p) {
+		if err := os.Remove(p); err != nil || p == top {
+			return
+		}
+	}
+}
 
-	return dir, nil
+// cloneArgs returns the git clone options for cloning the URL into dir
+func (o CloneOptions) cloneArgs(u *url.URL, dir string) []types.Option {
+	var args []types.Option
+	if o.Depth > 0 {
+		args = append(args, clone.Depth(strconv.Itoa(o.Depth)))
+	}
+	if o.ShallowSince != "" {
+		args = append(args, clone.ShallowSince(o.ShallowSince))
+	}
+	if o.Filter != "" {
+		args = append(args, func(g *types.Cmd) {
+			g.AddOptions("--filter=" + o.Filter)
+		})
+	}
+	if o.reference != "" {
+		args = append(args, func(g *types.Cmd) {
+			g.AddOptions("--reference-if-able")
+			g.AddOptions(o.reference)
+		})
+		args = append(args, git.Cond(o.Dissociate, clone.Dissociate))
+	}
+	// Branches and tags can be checked out by clone directly, commits are checked out afterwards
+	if ref := u.Fragment; ref != "" && !isCommitHash(ref) {
+		args = append(args, clone.Branch(ref))
+	}
+	return append(args, clone.Repository(remoteURL(u)), clone.Directory(dir))
+}
+
+// windowsReservedNames are device names that cannot be used as file names on Windows,
+// with or without an extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// checkSegments returns an *UnsafeDirectoryError if any slash-separated segment of p could
+// traverse directories or is not a portable file name
+func checkSegments(p string) error {
+	for _, seg := range strings.Split(p, "/") {
+		var reason string
+		name, _, _ := strings.Cut(seg, ".")
+		switch {
+		case seg == "" || seg == ".":
+			continue
+		case strings.Trim(seg, ".") == "":
+			reason = fmt.Sprintf("contains %q segment", seg)
+		case strings.ContainsRune(seg, '\\'):
+			reason = "contains a backslash"
+		case strings.ContainsFunc(seg, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+			reason = "contains a control character"
+		case windowsReservedNames[strings.ToUpper(strings.TrimSpace(name))]:
+			reason = fmt.Sprintf("%q is a reserved name on Windows", seg)
+		default:
+			continue
+		}
+		return &UnsafeDirectoryError{Dir: p, Reason: reason}
+	}
+	return nil
+}
+
+// within reports whether dir is strictly inside root
+func within(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != "." && filepath.IsLocal(rel)
+}
+
+// rootOf returns the innermost of the GETPATH roots that holds dir, or an empty string if
+// none does
+func rootOf(paths []string, dir string) string {
+	var root string
+	for _, p := range paths {
+		if within(p, dir) && len(p) > len(root) {
+			root = p
+		}
+	}
+	return root
+}
+
+// existingDir returns dir if it holds a repository, or else the directory at the same
+// relative path under another GETPATH entry that does. Otherwise dir is returned.
+func existingDir(paths []string, dir string) string {
+	if isGitRepository(dir) {
+		return dir
+	}
+	for _, root := range paths {
+		if !within(root, dir) {
+			continue
+		}
+		rel, _ := filepath.Rel(root, dir)
+		for _, other := range paths {
+			if d := filepath.Join(other, rel); isGitRepository(d) {
+				return d
+			}
+		}
+	}
+	return dir
 }
 
 func isGitRepository(path string) bool {
@@ -1,12 +1,19 @@
 package get
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ldez/go-git-cmd-wrapper/v2/config"
 	"github.com/ldez/go-git-cmd-wrapper/v2/git"
@@ -70,6 +77,18 @@ func TestAbsolutePath(t *testing.T) {
 			envGetpath: "~otheruser/src",
 			wantErr:    true,
 		},
+		"env var getpath list": {
+			envGetpath:   envGetpath + string(os.PathListSeparator) + configGetpath,
+			expectedPath: envGetpath,
+		},
+		"git config getpath list": {
+			gitConfigGetpath: string(os.PathListSeparator) + configGetpath + string(os.PathListSeparator) + envGetpath,
+			expectedPath:     configGetpath,
+		},
+		"relative entry in GETPATH list": {
+			envGetpath: envGetpath + string(os.PathListSeparator) + "test",
+			wantErr:    true,
+		},
 	}
 
 	for name, c := range cases {
@@ -101,6 +120,116 @@ func TestAbsolutePath(t *testing.T) {
 	})
 }
 
+func TestAbsolutePaths(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	cases := map[string]struct {
+		gitConfigGetpath string
+		envGetpath       string
+		want             []string
+	}{
+		"single entry": {
+			envGetpath: first,
+			want:       []string{first},
+		},
+		"list in order": {
+			envGetpath: second + string(os.PathListSeparator) + first,
+			want:       []string{second, first},
+		},
+		"empty and duplicate entries are skipped": {
+			gitConfigGetpath: first + string(os.PathListSeparator) + string(os.PathListSeparator) + second + string(os.PathListSeparator) + first,
+			want:             []string{first, second},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			setupEnv(t, c.gitConfigGetpath, c.envGetpath)
+
+			got, err := AbsolutePaths()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("unexpected paths:\n\t(GOT): %#v\n\t(WNT): %#v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestAbsolutePathFor(t *testing.T) {
+	defaultGetpath := t.TempDir()
+	workGetpath := t.TempDir()
+	orgGetpath := t.TempDir()
+	envGetpath := t.TempDir()
+
+	cases := map[string]struct {
+		remote     string
+		envGetpath string
+		want       string
+	}{
+		"unscoped": {
+			remote: "github.com/arbourd/git-get",
+			want:   defaultGetpath,
+		},
+		"host scope": {
+			remote: "git.corp.example/team/repo",
+			want:   workGetpath,
+		},
+		"host scope is case insensitive": {
+			remote: "https://Git.Corp.Example/team/repo",
+			want:   workGetpath,
+		},
+		"longest scope wins": {
+			remote: "git@github.com:myorg/repo.git",
+			want:   orgGetpath,
+		},
+		"scope matches whole segments": {
+			remote: "github.com/myorganization/repo",
+			want:   defaultGetpath,
+		},
+		"aliases are not scopes": {
+			remote: "https://alias/team/repo",
+			want:   defaultGetpath,
+		},
+		"env var getpath overrides scopes": {
+			remote:     "git.corp.example/team/repo",
+			envGetpath: envGetpath,
+			want:       envGetpath,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			setupConfig(t, map[string]string{
+				"get.path":                   defaultGetpath,
+				"get.git.corp.example.path":  workGetpath,
+				"get.github.com/myorg.path":  orgGetpath,
+				"get.github.com/myorg.depth": "1",
+				"get.alias.path":             "github.com",
+			})
+			t.Setenv("GETPATH", c.envGetpath)
+
+			u, err := ParseURL(c.remote)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := AbsolutePathFor(u)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("unexpected path:\n\t(GOT): %#v\n\t(WNT): %#v", got, c.want)
+			}
+		})
+	}
+}
+
 func TestShortPath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -135,10 +264,18 @@ func TestShortPath(t *testing.T) {
 }
 
 func TestParseURL(t *testing.T) {
+	setupConfig(t, map[string]string{
+		"get.alias.gh":   "github.com",
+		"get.alias.me":   "github.com/arbourd/",
+		"get.alias.ghs":  "git@github.com:",
+		"get.alias.work": "ssh://git@git.corp.example",
+	})
+
 	cases := map[string]struct {
-		remote  string
-		want    string
-		wantErr bool
+		remote   string
+		want     string
+		wantHost string
+		wantErr  bool
 	}{
 		"git protocol": {
 			remote: "git://github.com/arbourd/git-get.git",
@@ -153,9 +290,64 @@ func TestParseURL(t *testing.T) {
 			want:   "ssh://git@github.com/arbourd/git-get.git",
 		},
 		"no protocol": {
-			remote: "github.com/arbourd/git-get",
+			remote:   "github.com/arbourd/git-get",
+			want:     "https://github.com/arbourd/git-get",
+			wantHost: "github.com",
+		},
+		"tag ref": {
+			remote: "github.com/arbourd/git-get@v1.2.3",
+			want:   "https://github.com/arbourd/git-get#v1.2.3",
+		},
+		"branch ref with slash": {
+			remote: "https://github.com/arbourd/git-get.git@feature/x",
+			want:   "https://github.com/arbourd/git-get.git#feature/x",
+		},
+		"ssh ref": {
+			remote: "git@github.com:arbourd/git-get.git@main",
+			want:   "ssh://git@github.com/arbourd/git-get.git#main",
+		},
+		"user info is not a ref": {
+			remote: "https://user@github.com/arbourd/git-get",
+			want:   "https://user@github.com/arbourd/git-get",
+		},
+		"empty ref": {
+			remote:  "github.com/arbourd/git-get@",
+			wantErr: true,
+		},
+		"option-like ref": {
+			remote:  "github.com/arbourd/git-get@--upload-pack=evil",
+			wantErr: true,
+		},
+		"alias": {
+			remote:   "gh:arbourd/git-get",
+			want:     "https://github.com/arbourd/git-get",
+			wantHost: "github.com",
+		},
+		"alias is case insensitive": {
+			remote: "GH:arbourd/git-get",
 			want:   "https://github.com/arbourd/git-get",
 		},
+		"alias with ref": {
+			remote: "gh:arbourd/git-get@v1",
+			want:   "https://github.com/arbourd/git-get#v1",
+		},
+		"alias with path": {
+			remote: "me:git-get",
+			want:   "https://github.com/arbourd/git-get",
+		},
+		"alias with scheme": {
+			remote:   "work:team/repo",
+			want:     "ssh://git@git.corp.example/team/repo",
+			wantHost: "git.corp.example",
+		},
+		"scp-like alias": {
+			remote: "ghs:arbourd/git-get.git",
+			want:   "ssh://git@github.com/arbourd/git-get.git",
+		},
+		"unknown alias": {
+			remote: "nope:arbourd/git-get",
+			want:   "nope:arbourd/git-get",
+		},
 		"invalid url": {
 			remote:  "github.com/arbourd/git-get%x",
 			want:    "https://github.com/arbourd/git-get",
@@ -173,12 +365,127 @@ func TestParseURL(t *testing.T) {
 				t.Fatalf("expected error:\n\t(GOT): nil\n\t")
 			} else if url != nil && url.String() != c.want {
 				t.Fatalf("unexpected parsed url string:\n\t(GOT): %#v\n\t(WNT): %#v", url.String(), c.want)
+			} else if c.wantHost != "" && url.Host != c.wantHost {
+				t.Fatalf("unexpected parsed url host:\n\t(GOT): %#v\n\t(WNT): %#v", url.Host, c.wantHost)
+			}
+		})
+	}
+}
+
+func TestParseURLDefaults(t *testing.T) {
+	cases := map[string]struct {
+		remote  string
+		config  map[string]string
+		want    string
+		wantErr bool
+	}{
+		"owner and repo": {
+			remote: "arbourd/git-get",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "https://github.com/arbourd/git-get",
+		},
+		"owner and repo with ref": {
+			remote: "arbourd/git-get@v1",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "https://github.com/arbourd/git-get#v1",
+		},
+		"subgroups": {
+			remote: "gitlab-org/dev-subdepartment/repo",
+			config: map[string]string{"get.defaultHost": "gitlab.com"},
+			want:   "https://gitlab.com/gitlab-org/dev-subdepartment/repo",
+		},
+		"default host with scheme": {
+			remote: "team/repo",
+			config: map[string]string{"get.defaultHost": "ssh://git@git.corp.example/"},
+			want:   "ssh://git@git.corp.example/team/repo",
+		},
+		"repo": {
+			remote: "git-get",
+			config: map[string]string{"get.defaultHost": "github.com", "get.defaultOwner": "arbourd"},
+			want:   "https://github.com/arbourd/git-get",
+		},
+		"repo with ref": {
+			remote: "git-get@v1",
+			config: map[string]string{"get.defaultHost": "github.com", "get.defaultOwner": "arbourd"},
+			want:   "https://github.com/arbourd/git-get#v1",
+		},
+		"repo with a dot": {
+			remote: "socket.io",
+			config: map[string]string{"get.defaultHost": "github.com", "get.defaultOwner": "socketio"},
+			want:   "https://github.com/socketio/socket.io",
+		},
+		"repo with a dot and ref": {
+			remote: "vue.js@v2",
+			config: map[string]string{"get.defaultHost": "github.com", "get.defaultOwner": "vuejs"},
+			want:   "https://github.com/vuejs/vue.js#v2",
+		},
+		"repo with a dot without defaults": {
+			remote:  "socket.io",
+			wantErr: true,
+		},
+		"owner and repo without default host": {
+			remote:  "arbourd/git-get",
+			wantErr: true,
+		},
+		"repo without default owner": {
+			remote:  "git-get",
+			config:  map[string]string{"get.defaultHost": "github.com"},
+			wantErr: true,
+		},
+		"repo without default host": {
+			remote:  "git-get",
+			config:  map[string]string{"get.defaultOwner": "arbourd"},
+			wantErr: true,
+		},
+		"host with a dot": {
+			remote: "gitlab.com/arbourd/git-get",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "https://gitlab.com/arbourd/git-get",
+		},
+		"localhost": {
+			remote: "localhost/arbourd/git-get",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "https://localhost/arbourd/git-get",
+		},
+		"host without a dot and scheme": {
+			remote: "https://gitserver/team/repo",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "https://gitserver/team/repo",
+		},
+		"scp-like host without a dot": {
+			remote: "git@gitserver:team/repo",
+			config: map[string]string{"get.defaultHost": "github.com"},
+			want:   "ssh://git@gitserver/team/repo",
+		},
+		"alias": {
+			remote: "gl:arbourd/git-get",
+			config: map[string]string{"get.defaultHost": "github.com", "get.alias.gl": "gitlab.com"},
+			want:   "https://gitlab.com/arbourd/git-get",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			setupConfig(t, c.config)
+
+			u, err := ParseURL(c.remote)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): %s\n", u)
+			} else if u != nil && u.String() != c.want {
+				t.Fatalf("unexpected parsed url string:\n\t(GOT): %#v\n\t(WNT): %#v", u.String(), c.want)
 			}
 		})
 	}
 }
 
 func TestDirectory(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
 	cases := map[string]struct {
 		url  *url.URL
 		want string
@@ -230,14 +537,187 @@ func TestDirectory(t *testing.T) {
 	}
 }
 
+func TestDirectoryLayout(t *testing.T) {
+	github := &url.URL{Scheme: "https", Host: "github.com", Path: "/arbourd/git-get.git"}
+	gitlab := &url.URL{Scheme: "https", Host: "gitlab.com", Path: "/gitlab-org/dev-subdepartment/ai-dev-promptcollection"}
+
+	cases := map[string]struct {
+		config  map[string]string
+		url     *url.URL
+		want    string
+		wantErr bool
+	}{
+		"default": {
+			url:  gitlab,
+			want: "gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection",
+		},
+		"owner and repo": {
+			config: map[string]string{"get.layout": "{owner}/{repo}"},
+			url:    github,
+			want:   "arbourd/git-get",
+		},
+		"repo only": {
+			config: map[string]string{"get.layout": "{repo}"},
+			url:    github,
+			want:   "git-get",
+		},
+		"subgroups are part of owner": {
+			config: map[string]string{"get.layout": "{owner}/{repo}"},
+			url:    gitlab,
+			want:   "gitlab-org/dev-subdepartment/ai-dev-promptcollection",
+		},
+		"literal text": {
+			config: map[string]string{"get.layout": "{host}/forks/{repo}"},
+			url:    github,
+			want:   "github.com/forks/git-get",
+		},
+		"host override": {
+			config: map[string]string{
+				"get.layout":            "{host}/{owner}/{repo}",
+				"get.github.com.layout": "{repo}",
+			},
+			url:  github,
+			want: "git-get",
+		},
+		"other host ignored": {
+			config: map[string]string{"get.github.com.layout": "{repo}"},
+			url:    gitlab,
+			want:   "gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection",
+		},
+		"unknown placeholder": {
+			config:  map[string]string{"get.layout": "{org}/{repo}"},
+			url:     github,
+			wantErr: true,
+		},
+		"missing repo": {
+			config:  map[string]string{"get.layout": "{host}/{owner}"},
+			url:     github,
+			wantErr: true,
+		},
+		"absolute": {
+			config:  map[string]string{"get.layout": "/tmp/{repo}"},
+			url:     github,
+			wantErr: true,
+		},
+		"parent segments": {
+			config:  map[string]string{"get.layout": "../{repo}"},
+			url:     github,
+			wantErr: true,
+		},
+		"no repository in url": {
+			config:  map[string]string{"get.layout": "{repo}"},
+			url:     &url.URL{Scheme: "https", Host: "github.com"},
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			setupConfig(t, c.config)
+
+			dir, err := Directory(c.url)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): nil\n")
+			} else if err == nil && dir != filepath.FromSlash(c.want) {
+				t.Fatalf("unexpected directory:\n\t(GOT): %#v\n\t(WNT): %#v", dir, filepath.FromSlash(c.want))
+			}
+		})
+	}
+}
+
+func TestDirectoryUnsafe(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	cases := map[string]string{
+		"parent segments":         "https://evil.com/../../.ssh",
+		"encoded parent segments": "https://evil.com/a%2F..%2F..%2F.ssh",
+		"parent host":             "git@..:../.ssh",
+		"dots only":               "https://evil.com/owner/...",
+		"backslashes":             `https://evil.com/a\..\..\.ssh`,
+		"control character":       "https://evil.com/owner/repo%0A",
+		"reserved name":           "https://evil.com/owner/con",
+		"reserved name with ext":  "https://evil.com/owner/NUL.git",
+		"reserved host":           "git@aux:owner/repo",
+		"reserved port name":      "https://evil.com/lpt1/repo",
+		"parent before suffix":    "https://evil.com/...git",
+		"owner parent suffix":     "https://evil.com/a/...git",
+		"empty repo name":         "https://github.com/a/.git",
+		"dot repo name":           "https://github.com/a/./",
+		"host only":               "https://github.com",
+	}
+
+	for name, remote := range cases {
+		t.Run(name, func(t *testing.T) {
+			u, err := ParseURL(remote)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			dir, err := Directory(u)
+			var unsafe *UnsafeDirectoryError
+			if !errors.As(err, &unsafe) {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v (dir %q)\n\t(WNT): *UnsafeDirectoryError", err, dir)
+			}
+		})
+	}
+}
+
+func TestCloneUnsafe(t *testing.T) {
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+	u := &url.URL{Scheme: "https", Host: "github.com", Path: "arbourd/git-get"}
+
+	cases := map[string]struct {
+		url *url.URL
+		dir string
+	}{
+		"unsafe url": {
+			url: &url.URL{Scheme: "https", Host: "evil.com", Path: "/../../.ssh"},
+			dir: filepath.Join(getpath, "evil.com"),
+		},
+		"outside GETPATH": {
+			url: u,
+			dir: filepath.Join(t.TempDir(), "github.com/arbourd/git-get"),
+		},
+		"escapes GETPATH": {
+			url: u,
+			dir: filepath.Join(getpath, "..", "git-get"),
+		},
+		"GETPATH itself": {
+			url: u,
+			dir: getpath,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Clone(c.url, c.dir, CloneOptions{})
+			var unsafe *UnsafeDirectoryError
+			if !errors.As(err, &unsafe) {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): *UnsafeDirectoryError", err)
+			}
+			if _, err := os.Stat(c.dir); err == nil && c.dir != getpath {
+				t.Fatalf("expected %s not to be created", c.dir)
+			}
+		})
+	}
+}
+
 func TestClone(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping network test")
 	}
 	dir := t.TempDir()
+	t.Setenv("GETPATH", dir)
 
 	cases := map[string]struct {
 		url          *url.URL
+		opts         CloneOptions
 		expectedPath string
 		wantErr      bool
 	}{
@@ -258,6 +738,15 @@ func TestClone(t *testing.T) {
 			},
 			expectedPath: filepath.Join(dir, "github.com/arbourd/git-get"),
 		},
+		"shallow blobless clone github": {
+			url: &url.URL{
+				Scheme: "https",
+				Host:   "github.com",
+				Path:   "arbourd/git-get",
+			},
+			opts:         CloneOptions{Depth: 1, Filter: "blob:none"},
+			expectedPath: filepath.Join(dir, "shallow/github.com/arbourd/git-get"),
+		},
 		"clone gitlab subgroups": {
 			url: &url.URL{
 				Scheme: "https",
@@ -286,14 +775,394 @@ func TestClone(t *testing.T) {
 
 	for name, c := range cases {
 		t.Run(name, func(t *testing.T) {
-			path, err := Clone(c.url, c.expectedPath)
+			result, err := Clone(c.url, c.expectedPath, c.opts)
 
 			if err != nil && !c.wantErr {
 				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
 			} else if err == nil && c.wantErr {
 				t.Fatalf("expected error:\n\t(GOT): nil\n\t")
-			} else if path != c.expectedPath {
-				t.Fatalf("unexpected path:\n\t(GOT): %#v\n\t(WNT): %#v", path, c.expectedPath)
+			} else if result.Directory != c.expectedPath {
+				t.Fatalf("unexpected path:\n\t(GOT): %#v\n\t(WNT): %#v", result.Directory, c.expectedPath)
+			}
+		})
+	}
+}
+
+func TestCloneResult(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+	dir := filepath.Join(getpath, "local", "repo")
+
+	// Credentials and the ref are not part of the result's URL
+	u := *remote
+	u.User = url.UserPassword("user", "secret")
+	u.Fragment = "v1"
+
+	for _, want := range []CloneResult{
+		{URL: remote.String(), Directory: dir},
+		{URL: remote.String(), Directory: dir, Existing: true},
+	} {
+		got, err := Clone(&u, dir, CloneOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Fatalf("unexpected result:\n\t(GOT): %#v\n\t(WNT): %#v", got, want)
+		}
+	}
+}
+
+func TestCloneRef(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, first := fixtureRepo(t)
+
+	cases := map[string]struct {
+		ref        string
+		opts       CloneOptions
+		existing   bool
+		upstream   bool
+		wantHead   string
+		wantBranch string
+	}{
+		"tag": {
+			ref:      "v1",
+			wantHead: first,
+		},
+		"branch": {
+			ref:        "feature",
+			wantBranch: "feature",
+		},
+		"commit": {
+			ref:      first,
+			wantHead: first,
+		},
+		"commit in shallow clone": {
+			ref:      first,
+			opts:     CloneOptions{Depth: 1},
+			wantHead: first,
+		},
+		"existing repository": {
+			ref:      "v1",
+			existing: true,
+			wantHead: first,
+		},
+		"existing repository, branch moved upstream": {
+			ref:        "main",
+			existing:   true,
+			upstream:   true,
+			wantBranch: "main",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+			if c.existing {
+				if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+			wantHead := c.wantHead
+			if c.upstream {
+				// Leave the local branch behind origin, as if the remote moved after cloning
+				gitCmd(t, dir, "reset", "--quiet", "--hard", "HEAD~1")
+				wantHead = gitCmd(t, dir, "rev-parse", "origin/main")
+			}
+
+			u := *remote
+			u.Fragment = c.ref
+			if _, err := Clone(&u, dir, c.opts); err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+
+			if wantHead != "" {
+				if head := gitCmd(t, dir, "rev-parse", "HEAD"); head != wantHead {
+					t.Fatalf("unexpected HEAD:\n\t(GOT): %s\n\t(WNT): %s", head, wantHead)
+				}
+			}
+			if c.wantBranch != "" {
+				if branch := gitCmd(t, dir, "branch", "--show-current"); branch != c.wantBranch {
+					t.Fatalf("unexpected branch:\n\t(GOT): %s\n\t(WNT): %s", branch, c.wantBranch)
+				}
+			}
+		})
+	}
+}
+
+// racingBackend clones the repository to dir before cloning it as asked, as another clone
+// of the same repository would if it finished first
+type racingBackend struct {
+	ExecBackend
+	dir string
+}
+
+func (b racingBackend) Clone(ctx context.Context, u *url.URL, dir string, opts CloneOptions) error {
+	if err := b.ExecBackend.Clone(ctx, u, b.dir, opts); err != nil {
+		return err
+	}
+	return b.ExecBackend.Clone(ctx, u, dir, opts)
+}
+
+func TestCloneRace(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+	dir := filepath.Join(getpath, "repo")
+
+	got, err := Clone(remote, dir, CloneOptions{Backend: racingBackend{dir: dir}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (CloneResult{URL: remote.String(), Directory: dir, Existing: true}); got != want {
+		t.Fatalf("unexpected result:\n\t(GOT): %#v\n\t(WNT): %#v", got, want)
+	}
+	entries, err := os.ReadDir(getpath)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the repository in GETPATH, got: %v %v", entries, err)
+	}
+}
+
+func TestCloneGetpathList(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	first := t.TempDir()
+	legacy := t.TempDir()
+	t.Setenv("GETPATH", legacy)
+	existing := filepath.Join(legacy, "local", "repo")
+	if _, err := Clone(remote, existing, CloneOptions{}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	t.Setenv("GETPATH", first+string(os.PathListSeparator)+legacy)
+	got, err := Clone(remote, filepath.Join(first, "local", "repo"), CloneOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (CloneResult{URL: remote.String(), Directory: existing, Existing: true}); got != want {
+		t.Fatalf("unexpected result:\n\t(GOT): %#v\n\t(WNT): %#v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(first, "local")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected nothing to be created in the first GETPATH entry, got: %v", err)
+	}
+}
+
+func TestCloneFailureCleanup(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		ref  string
+		seed []string
+		want []string
+	}{
+		"missing branch removes created parents": {
+			ref: "does-not-exist",
+		},
+		"missing commit removes created parents": {
+			ref: "0123456789abcdef0123456789abcdef01234567",
+		},
+		"existing parents are kept": {
+			ref:  "does-not-exist",
+			seed: []string{"host/owner/other"},
+			want: []string{"host", "host/owner", "host/owner/other"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			for _, d := range c.seed {
+				if err := os.MkdirAll(filepath.Join(getpath, d), 0755); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+
+			u := *remote
+			u.Fragment = c.ref
+			if _, err := Clone(&u, filepath.Join(getpath, "host/owner/repo"), CloneOptions{}); err == nil {
+				t.Fatal("expected error:\n\t(GOT): nil")
+			}
+
+			var got []string
+			_ = filepath.WalkDir(getpath, func(p string, d fs.DirEntry, err error) error {
+				if p != getpath {
+					rel, _ := filepath.Rel(getpath, p)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("unexpected GETPATH contents:\n\t(GOT): %v\n\t(WNT): %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCloneContext(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	cases := map[string]struct {
+		ctx     context.Context
+		wantErr error
+	}{
+		"background": {
+			ctx: context.Background(),
+		},
+		"canceled": {
+			ctx:     canceled,
+			wantErr: context.Canceled,
+		},
+		"deadline exceeded": {
+			ctx:     expired,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "host/owner/repo")
+
+			_, err := CloneContext(c.ctx, remote, dir, CloneOptions{})
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, c.wantErr)
+			}
+			if err := ProbeContext(c.ctx, remote); !errors.Is(err, c.wantErr) {
+				t.Fatalf("unexpected probe error:\n\t(GOT): %v\n\t(WNT): %v", err, c.wantErr)
+			}
+
+			entries, _ := os.ReadDir(getpath)
+			if c.wantErr != nil && len(entries) > 0 {
+				t.Fatalf("expected GETPATH to be empty, got: %v", entries)
+			}
+			if c.wantErr == nil && !isGitRepository(dir) {
+				t.Fatalf("expected %s to be a git repository", dir)
+			}
+		})
+	}
+}
+
+func TestConfigCloneOptions(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	u := &url.URL{Scheme: "https", Host: "github.com", Path: "arbourd/git-get"}
+
+	cases := map[string]struct {
+		config  map[string]string
+		want    CloneOptions
+		wantErr bool
+	}{
+		"unset": {
+			want: CloneOptions{},
+		},
+		"section defaults": {
+			config: map[string]string{
+				"get.depth":        "1",
+				"get.shallowSince": "2024-01-01",
+				"get.filter":       "tree:0",
+			},
+			want: CloneOptions{Depth: 1, ShallowSince: "2024-01-01", Filter: "tree:0"},
+		},
+		"host overrides section": {
+			config: map[string]string{
+				"get.filter":            "tree:0",
+				"get.github.com.filter": "blob:none",
+			},
+			want: CloneOptions{Filter: "blob:none"},
+		},
+		"other host ignored": {
+			config: map[string]string{
+				"get.gitlab.com.filter": "blob:none",
+			},
+			want: CloneOptions{},
+		},
+		"invalid depth": {
+			config: map[string]string{
+				"get.depth": "shallow",
+			},
+			wantErr: true,
+		},
+		"cache": {
+			config: map[string]string{
+				"get.cache":                 "true",
+				"get.github.com.dissociate": "yes",
+			},
+			want: CloneOptions{Cache: true, Dissociate: true},
+		},
+		"cache disabled for host": {
+			config: map[string]string{
+				"get.cache":            "true",
+				"get.github.com.cache": "off",
+			},
+			want: CloneOptions{},
+		},
+		"invalid cache": {
+			config: map[string]string{
+				"get.cache": "sometimes",
+			},
+			wantErr: true,
+		},
+		"backend": {
+			config: map[string]string{
+				"get.backend": "go-git",
+			},
+			want: CloneOptions{Backend: GoGitBackend{}},
+		},
+		"invalid backend": {
+			config: map[string]string{
+				"get.backend": "libgit2",
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			setupConfig(t, c.config)
+
+			opts, err := ConfigCloneOptions(u)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): nil\n")
+			} else if opts != c.want {
+				t.Fatalf("unexpected options:\n\t(GOT): %#v\n\t(WNT): %#v", opts, c.want)
 			}
 		})
 	}
@@ -324,3 +1193,48 @@ func setupEnv(t *testing.T, gitConfigGetpath, envGetpath string) {
 		}
 	}
 }
+
+// setupConfig replaces the global Git config with a fresh one containing the given entries.
+func setupConfig(t *testing.T, entries map[string]string) {
+	t.Helper()
+	if err := gitConfigGlobalFixture(t); err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	for k, v := range entries {
+		if _, err := git.Config(config.Global, config.Entry(k, v)); err != nil {
+			t.Fatalf("unable to set git config %s: %s", k, err)
+		}
+	}
+}
+
+// fixtureRepo creates a local repository with two commits on main, a tag "v1" on the first
+// commit and a branch "feature" with a third commit. It returns the file URL and the first commit.
+func fixtureRepo(t *testing.T) (*url.URL, string) {
+	t.Helper()
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "--quiet", "--initial-branch=main")
+	gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "first")
+	first := gitCmd(t, dir, "rev-parse", "HEAD")
+	gitCmd(t, dir, "tag", "v1")
+	gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "second")
+	gitCmd(t, dir, "checkout", "--quiet", "-b", "feature")
+	gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "third")
+	gitCmd(t, dir, "checkout", "--quiet", "main")
+
+	path := filepath.ToSlash(dir)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return &url.URL{Scheme: "file", Path: path}, first
+}
+
+// gitCmd runs git in dir and returns its trimmed output, failing the test on error.
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,192 @@
+package get
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// GoGitBackend clones in-process with go-git, so that no git command is needed to clone a
+// new repository. It does not read the Git config, so credential helpers and url.insteadOf
+// rewrites are not applied; SSH remotes authenticate with the SSH agent. ShallowSince,
+// Filter and Cache are not supported, and go-git runs git-upload-pack for file remotes.
+// Existing repositories are also checked and switched to a ref in-process.
+type GoGitBackend struct{}
+
+// Probe lists the references of the remote repository
+func (GoGitBackend) Probe(ctx context.Context, u *url.URL) error {
+	remote := gogit.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: gogit.DefaultRemoteName,
+		URLs: []string{remoteURL(u)},
+	})
+	_, err := remote.ListContext(ctx, &gogit.ListOptions{})
+	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return goGitRemoteError(u, err)
+	}
+	return nil
+}
+
+// Clone clones with go-git, checking out the ref as a branch or tag, or else as a commit
+func (GoGitBackend) Clone(ctx context.Context, u *url.URL, dir string, opts CloneOptions) error {
+	switch {
+	case opts.ShallowSince != "":
+		return fmt.Errorf("shallow-since with the go-git backend: %w", errors.ErrUnsupported)
+	case opts.Filter != "":
+		return fmt.Errorf("filter with the go-git backend: %w", errors.ErrUnsupported)
+	case opts.reference != "":
+		return fmt.Errorf("object cache with the go-git backend: %w", errors.ErrUnsupported)
+	}
+
+	ref := u.Fragment
+	cloneOpts := &gogit.CloneOptions{
+		URL:   remoteURL(u),
+		Depth: opts.Depth,
+	}
+	if ref != "" && !isCommitHash(ref) {
+		// go-git expands the short name to a branch or tag, as git clone --branch does
+		cloneOpts.ReferenceName = plumbing.ReferenceName(ref)
+	}
+	repo, err := gogit.PlainCloneContext(ctx, dir, false, cloneOpts)
+	if err != nil {
+		return goGitRemoteError(u, err)
+	}
+
+	if ref == "" || !isCommitHash(ref) {
+		return nil
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("resolving ref %q: %w", ref, err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("checking out ref %q: %w", ref, err)
+	}
+	if err := w.Checkout(&gogit.CheckoutOptions{Hash: *hash}); err != nil {
+		return fmt.Errorf("checking out ref %q: %w", ref, err)
+	}
+	return nil
+}
+
+// IsRepository reports whether go-git can open the repository at dir
+func (GoGitBackend) IsRepository(dir string) bool {
+	_, err := gogit.PlainOpen(dir)
+	return err == nil
+}
+
+// Remotes reads the remotes from the repository's config
+func (GoGitBackend) Remotes(dir string) (map[string]string, error) {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository: go-git: %w", err)
+	}
+	list, err := repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("reading remotes: go-git: %w", err)
+	}
+
+	remotes := make(map[string]string)
+	for _, r := range list {
+		if c := r.Config(); len(c.URLs) > 0 {
+			remotes[c.Name] = c.URLs[0]
+		}
+	}
+	return remotes, nil
+}
+
+// AddRemote adds the remote to the repository's config
+func (GoGitBackend) AddRemote(dir, name string, u *url.URL) error {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("opening repository: go-git: %w", err)
+	}
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: name, URLs: []string{remoteURL(u)}}); err != nil {
+		return fmt.Errorf("adding remote %q: go-git: %w", name, err)
+	}
+	return nil
+}
+
+// SwitchRef fetches from origin and checks out the ref as a local branch, a branch of origin,
+// a tag or a commit, in that order, as git checkout does. A branch with an upstream is then
+// pulled, which only fast-forwards.
+func (GoGitBackend) SwitchRef(ctx context.Context, dir, ref string, depth int) error {
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("opening repository: go-git: %w", err)
+	}
+	err = repo.FetchContext(ctx, &gogit.FetchOptions{RemoteName: gogit.DefaultRemoteName, Depth: depth, Tags: gogit.AllTags})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fetching: go-git: %w", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("checking out ref %q: go-git: %w", ref, err)
+	}
+	branch := plumbing.NewBranchReferenceName(ref)
+	tracking := plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, ref)
+	switch {
+	case hasReference(repo, branch):
+		err = w.Checkout(&gogit.CheckoutOptions{Branch: branch})
+	case hasReference(repo, tracking):
+		// As git checkout does, create a local branch that tracks the branch of origin
+		r, _ := repo.Reference(tracking, true)
+		err = w.Checkout(&gogit.CheckoutOptions{Branch: branch, Hash: r.Hash(), Create: true})
+		if err == nil {
+			err = repo.CreateBranch(&gitconfig.Branch{Name: ref, Remote: gogit.DefaultRemoteName, Merge: branch})
+		}
+	default:
+		var hash *plumbing.Hash
+		hash, err = repo.ResolveRevision(plumbing.Revision(ref))
+		if err == nil {
+			err = w.Checkout(&gogit.CheckoutOptions{Hash: *hash})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("checking out ref %q: go-git: %w", ref, err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("reading config: go-git: %w", err)
+	}
+	upstream, ok := cfg.Branches[ref]
+	if !ok || !hasReference(repo, branch) {
+		return nil
+	}
+	head, err := repo.Head()
+	if err != nil || head.Name() != branch {
+		return nil
+	}
+	err = w.PullContext(ctx, &gogit.PullOptions{RemoteName: upstream.Remote, ReferenceName: upstream.Merge, Depth: depth})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fast-forwarding branch %q: go-git: %w", ref, err)
+	}
+	return nil
+}
+
+// hasReference reports whether the repository has the reference
+func hasReference(repo *gogit.Repository, name plumbing.ReferenceName) bool {
+	_, err := repo.Reference(name, true)
+	return err == nil
+}
+
+// goGitRemoteError wraps an error from go-git contacting the remote at u with ErrAuthFailed
+// if authentication failed, ErrRemoteNotFound if the repository does not exist, or else
+// returns it unchanged
+func goGitRemoteError(u *url.URL, err error) error {
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		return fmt.Errorf("%w at %s: go-git: %w", ErrAuthFailed, SanitizedURL(u), err)
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return fmt.Errorf("%w at %s: go-git: %w", ErrRemoteNotFound, SanitizedURL(u), err)
+	}
+	return fmt.Errorf("go-git: %w", err)
+}
@@ -0,0 +1,72 @@
+package get
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultLayout arranges repositories by host followed by their full path
+const DefaultLayout = "{host}/{owner}/{repo}"
+
+var placeholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Layout returns the directory layout template for the URL from the global Git config.
+// A host-scoped get.<host>.layout takes precedence over get.layout.
+//
+// The template may contain the placeholders {host}, {owner} and {repo}. {repo} is the last
+// segment of the URL path without ".git", and {owner} is every segment before it, which
+// may be more than one for GitLab subgroups.
+func Layout(u *url.URL) (string, error) {
+	layout := hostConfig(u, "layout")
+	if layout == "" {
+		return DefaultLayout, nil
+	}
+	if err := validateLayout(layout); err != nil {
+		return "", err
+	}
+	return layout, nil
+}
+
+// validateLayout returns an error if the layout has unknown placeholders or could place
+// repositories outside of GETPATH
+func validateLayout(layout string) error {
+	for _, p := range placeholderRe.FindAllString(layout, -1) {
+		switch p {
+		case "{host}", "{owner}", "{repo}":
+		default:
+			return fmt.Errorf("invalid layout %q: unknown placeholder %s", layout, p)
+		}
+	}
+
+	rest := placeholderRe.ReplaceAllString(layout, "x")
+	switch {
+	case strings.ContainsAny(rest, "{}"):
+		return fmt.Errorf("invalid layout %q: unbalanced braces", layout)
+	case !strings.Contains(layout, "{repo}"):
+		return fmt.Errorf("invalid layout %q: missing {repo}", layout)
+	case path.IsAbs(rest) || strings.HasPrefix(rest, `\`):
+		return fmt.Errorf("invalid layout %q: must be relative", layout)
+	}
+	return checkSegments(rest)
+}
+
+// expandLayout fills in the layout from the repository path returned by repositoryPath
+func expandLayout(layout, repoPath string) (string, error) {
+	host, rest, _ := strings.Cut(repoPath, "/")
+	var owner, repo string
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		owner, repo = rest[:i], rest[i+1:]
+	} else {
+		repo = rest
+	}
+
+	dir := strings.NewReplacer("{host}", host, "{owner}", owner, "{repo}", repo).Replace(layout)
+	dir = path.Clean(dir)
+	if dir == "." || dir == "/" {
+		return "", fmt.Errorf("no directory for %q with layout %q", repoPath, layout)
+	}
+	return strings.TrimPrefix(dir, "/"), nil
+}
@@ -0,0 +1,189 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// Manifest lists the repositories in a GETPATH so that it can be reproduced elsewhere
+type Manifest struct {
+	Repositories []ManifestEntry `json:"repositories"`
+}
+
+// ManifestEntry is a repository in a Manifest
+type ManifestEntry struct {
+	// URL is the remote the repository is cloned from, without any password
+	URL string `json:"url"`
+	// Directory is the path relative to GETPATH with forward slashes, eg: github.com/arbourd/git-get
+	Directory string `json:"directory"`
+	// Branch is checked out after cloning when set
+	Branch string `json:"branch,omitempty"`
+	// Depth creates a shallow clone when greater than zero
+	Depth int `json:"depth,omitempty"`
+}
+
+// Export returns a Manifest of the repositories under GETPATH whose name matches any of the
+// patterns, or of all repositories when none are given. Repositories without an origin
+// remote cannot be cloned again and are skipped. Repositories that cannot be read are left
+// out, and returned as a joined error along with the Manifest of the others.
+func Export(patterns ...string) (Manifest, error) {
+	repos, err := List(patterns...)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	m := Manifest{Repositories: []ManifestEntry{}}
+	var errs []error
+	for _, r := range repos {
+		e, ok, err := exportEntry(r)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			m.Repositories = append(m.Repositories, e)
+		}
+	}
+	return m, errors.Join(errs...)
+}
+
+// exportEntry returns the ManifestEntry of the repository, or false if it has no origin remote
+func exportEntry(r Repository) (ManifestEntry, bool, error) {
+	s, err := RepositoryStatus(r.Path)
+	if err != nil {
+		return ManifestEntry{}, false, fmt.Errorf("reading status of %s: %w", r.Name, err)
+	}
+	if s.Remote == "" {
+		return ManifestEntry{}, false, nil
+	}
+
+	depth, err := shallowDepth(r.Path)
+	if err != nil {
+		return ManifestEntry{}, false, fmt.Errorf("reading depth of %s: %w", r.Name, err)
+	}
+	return ManifestEntry{
+		URL:       stripPassword(s.Remote),
+		Directory: r.Name,
+		Branch:    s.Branch,
+		Depth:     depth,
+	}, true, nil
+}
+
+// ReadManifest decodes and validates a JSON Manifest
+func ReadManifest(r io.Reader) (Manifest, error) {
+	var m Manifest
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	for i, e := range m.Repositories {
+		switch {
+		case e.URL == "":
+			return Manifest{}, fmt.Errorf("repository %d: missing url", i)
+		case e.Directory == "":
+			return Manifest{}, fmt.Errorf("repository %d: missing directory", i)
+		case !filepath.IsLocal(filepath.FromSlash(e.Directory)):
+			return Manifest{}, fmt.Errorf("repository %d: directory %q is not within GETPATH", i, e.Directory)
+		case e.Depth < 0:
+			return Manifest{}, fmt.Errorf("repository %d: invalid depth %d", i, e.Depth)
+		}
+	}
+	return m, nil
+}
+
+// Write encodes the Manifest as indented JSON
+func (m Manifest) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Import clones the entry into its directory, relative to the GETPATH that AbsolutePathFor
+// returns for its URL, unless a repository already exists there. It returns the directory
+// and whether the repository was cloned.
+func (e ManifestEntry) Import() (string, bool, error) {
+	return e.ImportContext(context.Background())
+}
+
+// ImportContext is Import, stopping the clone when the context is done
+func (e ManifestEntry) ImportContext(ctx context.Context) (string, bool, error) {
+	u, err := ParseURL(e.URL)
+	if err != nil {
+		return "", false, fmt.Errorf("parsing url %q: %w", e.URL, err)
+	}
+
+	getpath, err := AbsolutePathFor(u)
+	if err != nil {
+		return "", false, fmt.Errorf("resolving GETPATH: %w", err)
+	}
+	dir := filepath.Join(getpath, filepath.FromSlash(e.Directory))
+	if isGitRepository(dir) {
+		return dir, false, nil
+	}
+	if e.Branch != "" {
+		u.Fragment = e.Branch
+	}
+
+	opts, err := ConfigCloneOptions(u)
+	if err != nil {
+		return "", false, err
+	}
+	if e.Depth > 0 {
+		opts.Depth = e.Depth
+	}
+
+	result, err := CloneContext(ctx, u, dir, opts)
+	if err != nil {
+		return "", false, err
+	}
+	return result.Directory, !result.Existing, nil
+}
+
+// shallowDepth returns the number of commits in the current branch of a shallow
+// repository, or zero if the repository has its full history
+func shallowDepth(dir string) (int, error) {
+	out, err := git.RevParse(global.UpperC(dir), func(g *types.Cmd) {
+		g.AddOptions("--is-shallow-repository")
+	})
+	if err != nil {
+		return 0, gitError("git rev-parse", out, err)
+	}
+	if strings.TrimSpace(out) != "true" {
+		return 0, nil
+	}
+
+	out, err = git.Raw("rev-list", global.UpperC(dir), func(g *types.Cmd) {
+		g.AddOptions("--count")
+		g.AddOptions("HEAD")
+	})
+	if err != nil {
+		return 0, gitError("git rev-list", out, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
+}
+
+// stripPassword removes any password from a remote URL, keeping the user name
+func stripPassword(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil {
+		return remote
+	}
+	if _, ok := u.User.Password(); !ok {
+		return remote
+	}
+	u.User = url.User(u.User.Username())
+	return u.String()
+}
@@ -0,0 +1,95 @@
+package get
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// ClonePlan describes what Clone would do, without doing it
+type ClonePlan struct {
+	// URL is the URL of the repository without user information or ref
+	URL string
+	// Directory is the directory the repository would be cloned to, or the existing
+	// repository in another GETPATH entry
+	Directory string
+	// Exists is set when Directory exists
+	Exists bool
+	// Repository is set when Directory holds a git repository, which would not be cloned
+	Repository bool
+	// Commands are the git commands Clone would run, in order, as arguments starting with
+	// git, with any password left out of the URL. Commands that only run after another
+	// fails, or when the ref is a branch with an upstream, are left out, and new repositories are shown cloned straight to Directory
+	// rather than to a temporary sibling. When a Backend other than ExecBackend would clone
+	// or check the repository, no commands are included.
+	Commands [][]string
+}
+
+// PlanClone returns what Clone would do with the same arguments, without running git or
+// creating any directories. As with Clone, an *UnsafeDirectoryError is returned if the URL
+// is unsafe or dir is not within GETPATH.
+func PlanClone(u *url.URL, dir string, opts CloneOptions) (ClonePlan, error) {
+	paths, dir, err := cloneDir(u, dir)
+	if err != nil {
+		return ClonePlan{}, err
+	}
+
+	plan := ClonePlan{URL: SanitizedURL(u), Directory: dir}
+	if u.User != nil {
+		redacted := *u
+		redacted.User = url.User(u.User.Username())
+		u = &redacted
+	}
+	b := opts.backend()
+	ref := u.Fragment
+	if _, err := os.Stat(dir); err == nil {
+		plan.Exists = true
+	}
+	_, isExec := b.(ExecBackend)
+	if b.IsRepository(dir) {
+		plan.Repository = true
+		if !isExec {
+			// Checked and switched in-process
+			return plan, nil
+		}
+		plan.Commands = append(plan.Commands, commandArgs("config", remoteURLsArgs(dir)...))
+		if ref != "" {
+			plan.Commands = append(plan.Commands,
+				commandArgs("fetch", fetchTagsArgs(dir)...),
+				commandArgs("checkout", checkoutArgs(dir, ref)...))
+		}
+		return plan, nil
+	}
+	if !isExec || plan.Exists {
+		// Cloned in-process, or not at all as the directory is occupied
+		return plan, nil
+	}
+
+	plan.Commands = append(plan.Commands, commandArgs("ls-remote", lsRemoteArgs(u)...))
+	if opts.Cache {
+		opts.reference, err = cachePath(rootOf(paths, dir), u)
+		if err != nil {
+			return ClonePlan{}, err
+		}
+		if _, err := os.Stat(filepath.Join(opts.reference, "HEAD")); err == nil {
+			plan.Commands = append(plan.Commands, commandArgs("fetch", mirrorFetchArgs(opts.reference)...))
+		} else {
+			plan.Commands = append(plan.Commands, commandArgs("clone", mirrorArgs(u, opts.reference)...))
+		}
+	}
+	plan.Commands = append(plan.Commands, commandArgs("clone", opts.cloneArgs(u, dir)...))
+	if ref != "" && isCommitHash(ref) {
+		plan.Commands = append(plan.Commands, commandArgs("checkout", checkoutArgs(dir, ref)...))
+	}
+	return plan, nil
+}
+
+// commandArgs returns the arguments git would be run with for the command and options
+func commandArgs(name string, options ...types.Option) []string {
+	g := types.NewCmd(name)
+	g.ApplyOptions(options...)
+	return slices.Concat([]string{g.Base}, g.BaseOptions, g.Options)
+}
@@ -0,0 +1,126 @@
+package get
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanClone(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	u := &url.URL{Scheme: "https", User: url.UserPassword("user", "secret"), Host: "github.com", Path: "arbourd/git-get"}
+	remote := "https://user@github.com/arbourd/git-get"
+
+	cases := map[string]struct {
+		ref          string
+		opts         CloneOptions
+		seed         string
+		wantExists   bool
+		wantRepo     bool
+		wantCommands func(dir, getpath string) [][]string
+	}{
+		"new repository": {
+			opts: CloneOptions{Depth: 1},
+			wantCommands: func(dir, _ string) [][]string {
+				return [][]string{
+					{"git", "ls-remote", remote},
+					{"git", "clone", "--depth", "1", remote, dir},
+				}
+			},
+		},
+		"commit": {
+			ref: "0123456",
+			wantCommands: func(dir, _ string) [][]string {
+				return [][]string{
+					{"git", "ls-remote", remote},
+					{"git", "clone", remote, dir},
+					{"git", "-C", dir, "checkout", "--quiet", "0123456"},
+				}
+			},
+		},
+		"cache": {
+			opts: CloneOptions{Cache: true},
+			wantCommands: func(dir, getpath string) [][]string {
+				mirror := filepath.Join(getpath, ".cache", "objects", "github.com", "arbourd", "git-get.git")
+				return [][]string{
+					{"git", "ls-remote", remote},
+					{"git", "clone", "--mirror", "--quiet", remote, mirror},
+					{"git", "clone", "--reference-if-able", mirror, remote, dir},
+				}
+			},
+		},
+		"existing repository": {
+			ref:        "v1",
+			seed:       ".git",
+			wantExists: true,
+			wantRepo:   true,
+			wantCommands: func(dir, _ string) [][]string {
+				return [][]string{
+					{"git", "-C", dir, "config", "--local", "--get-regexp", `^remote\..*\.url$`},
+					{"git", "-C", dir, "fetch", "--quiet", "--tags", "origin"},
+					{"git", "-C", dir, "checkout", "--quiet", "v1"},
+				}
+			},
+		},
+		"occupied": {
+			seed:         "README.md",
+			wantExists:   true,
+			wantCommands: func(string, string) [][]string { return nil },
+		},
+		"go-git backend": {
+			opts:         CloneOptions{Backend: GoGitBackend{}},
+			wantCommands: func(string, string) [][]string { return nil },
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "github.com", "arbourd", "git-get")
+			if c.seed != "" {
+				if err := os.MkdirAll(filepath.Join(dir, c.seed), 0755); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+
+			ru := *u
+			ru.Fragment = c.ref
+			plan, err := PlanClone(&ru, dir, c.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := ClonePlan{
+				URL:        "https://github.com/arbourd/git-get",
+				Directory:  dir,
+				Exists:     c.wantExists,
+				Repository: c.wantRepo,
+				Commands:   c.wantCommands(dir, getpath),
+			}
+			if !reflect.DeepEqual(plan, want) {
+				t.Fatalf("unexpected plan:\n\t(GOT): %#v\n\t(WNT): %#v", plan, want)
+			}
+
+			// Nothing is created for repositories that do not exist yet
+			if entries, _ := os.ReadDir(getpath); c.seed == "" && len(entries) > 0 {
+				t.Fatalf("expected GETPATH to be empty, got: %v", entries)
+			}
+		})
+	}
+}
+
+func TestPlanCloneUnsafe(t *testing.T) {
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+
+	u := &url.URL{Scheme: "https", Host: "github.com", Path: "arbourd/git-get"}
+	if _, err := PlanClone(u, t.TempDir(), CloneOptions{}); err == nil {
+		t.Fatal("expected error:\n\t(GOT): nil")
+	}
+}
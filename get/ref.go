@@ -0,0 +1,114 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/checkout"
+	"github.com/ldez/go-git-cmd-wrapper/v2/fetch"
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/merge"
+	"github.com/ldez/go-git-cmd-wrapper/v2/revparse"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+var commitHashRe = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// splitRef moves a ref following the repository path (repo@ref) into the URL fragment
+func splitRef(u *url.URL) error {
+	if path, ref, found := strings.Cut(u.Path, "@"); found {
+		if ref == "" {
+			return fmt.Errorf("empty ref after @")
+		}
+		if u.Fragment != "" {
+			return fmt.Errorf("multiple refs specified: %q and %q", ref, u.Fragment)
+		}
+		u.Path = path
+		u.Fragment = ref
+		u.RawFragment = ""
+	}
+
+	if strings.HasPrefix(u.Fragment, "-") {
+		return fmt.Errorf("invalid ref: %q", u.Fragment)
+	}
+	return nil
+}
+
+// remoteURL returns the URL as a string suitable for git, without the ref
+func remoteURL(u *url.URL) string {
+	r := *u
+	r.Fragment = ""
+	r.RawFragment = ""
+	return r.String()
+}
+
+// isCommitHash reports whether the ref looks like an abbreviated or full commit hash
+func isCommitHash(ref string) bool {
+	return commitHashRe.MatchString(ref)
+}
+
+// switchRef fetches from origin and checks out ref in the existing repository at dir. A
+// branch that tracks a remote branch is fast-forwarded to it, as a fresh clone would be.
+func switchRef(ctx context.Context, dir, ref string, depth int) error {
+	if _, err := git.FetchWithContext(ctx, fetchTagsArgs(dir)...); err != nil {
+		return fmt.Errorf("git fetch: %w", err)
+	}
+	if err := checkoutRef(ctx, dir, ref, depth); err != nil {
+		return err
+	}
+
+	// Tags and commits are checked out detached, and so have no upstream
+	if _, err := git.RevParseWithContext(ctx, upstreamArgs(dir)...); err != nil {
+		return nil
+	}
+	if out, err := git.MergeWithContext(ctx, fastForwardArgs(dir)...); err != nil {
+		return fmt.Errorf("fast-forwarding branch %q: %w", ref, gitError("git merge", out, err))
+	}
+	return nil
+}
+
+// checkoutRef checks out ref in the repository at dir. If the ref is not available locally,
+// as is common for commits in shallow clones, it is fetched from origin and checked out detached.
+func checkoutRef(ctx context.Context, dir, ref string, depth int) error {
+	if _, err := git.CheckoutWithContext(ctx, checkoutArgs(dir, ref)...); err == nil {
+		return nil
+	}
+
+	_, err := git.FetchWithContext(ctx, global.UpperC(dir), fetch.Quiet,
+		git.Cond(depth > 0, fetch.Depth(strconv.Itoa(depth))),
+		fetch.Remote("origin"), fetch.RefSpec(ref))
+	if err != nil {
+		return fmt.Errorf("fetching ref %q: %w", ref, err)
+	}
+	if _, err := git.CheckoutWithContext(ctx, global.UpperC(dir), checkout.Quiet, checkout.Detach, checkout.Branch("FETCH_HEAD")); err != nil {
+		return fmt.Errorf("checking out ref %q: %w", ref, err)
+	}
+	return nil
+}
+
+// fetchTagsArgs returns the options of the git fetch that switchRef runs before checking out
+func fetchTagsArgs(dir string) []types.Option {
+	return []types.Option{global.UpperC(dir), fetch.Quiet, fetch.Tags, fetch.Remote("origin")}
+}
+
+// upstreamArgs returns the options of the git rev-parse that checks whether the current
+// branch has an upstream
+func upstreamArgs(dir string) []types.Option {
+	return []types.Option{global.UpperC(dir), revparse.Verify, revparse.Quiet, revparse.Args("@{u}")}
+}
+
+// fastForwardArgs returns the options of the git merge that fast-forwards the current branch
+// to its upstream
+func fastForwardArgs(dir string) []types.Option {
+	return []types.Option{global.UpperC(dir), merge.Quiet, merge.FfOnly, merge.Commits("@{u}")}
+}
+
+// checkoutArgs returns the options of the git checkout of a ref that is available locally
+func checkoutArgs(dir, ref string) []types.Option {
+	return []types.Option{global.UpperC(dir), checkout.Quiet, checkout.Branch(ref)}
+}
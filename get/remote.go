@@ -0,0 +1,108 @@
+package get
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/config"
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// RemoteMismatchError is returned when an existing repository has no remote that points to
+// the requested repository, such as when a fork has been cloned in its place
+type RemoteMismatchError struct {
+	Dir string
+	URL string
+	// Remotes maps the name of each remote in the existing repository to its URL
+	Remotes map[string]string
+}
+
+func (e *RemoteMismatchError) Error() string {
+	if len(e.Remotes) == 0 {
+		return fmt.Sprintf("%s exists but has no remotes, expected %s", e.Dir, e.URL)
+	}
+
+	var remotes []string
+	for _, name := range slices.Sorted(maps.Keys(e.Remotes)) {
+		remotes = append(remotes, name+" "+e.Remotes[name])
+	}
+	return fmt.Sprintf("%s exists but no remote matches %s (%s)", e.Dir, e.URL, strings.Join(remotes, ", "))
+}
+
+// Is reports whether target is ErrDestinationOccupied
+func (e *RemoteMismatchError) Is(target error) bool {
+	return target == ErrDestinationOccupied
+}
+
+// checkRemote returns a *RemoteMismatchError unless a remote of the repository at dir points to
+// the same repository as the URL. If addRemote is set, the URL is added as a remote with that
+// name instead of returning an error.
+func checkRemote(b Backend, u *url.URL, dir, addRemote string) error {
+	remotes, err := b.Remotes(dir)
+	if err != nil {
+		return err
+	}
+	for _, r := range remotes {
+		if ru, err := ParseURL(r); err == nil && sameRepository(u, ru) {
+			return nil
+		}
+	}
+
+	if addRemote != "" {
+		return b.AddRemote(dir, addRemote, u)
+	}
+	return &RemoteMismatchError{Dir: dir, URL: SanitizedURL(u), Remotes: remotes}
+}
+
+// remoteURLs returns the URL of each remote in the repository at dir, keyed by remote name
+func remoteURLs(dir string) (map[string]string, error) {
+	remotes := make(map[string]string)
+	out, err := git.Config(remoteURLsArgs(dir)...)
+	if err != nil {
+		// git config exits with 1 when there are no matching keys
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return remotes, nil
+		}
+		return nil, gitError("git config", out, err)
+	}
+
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".url")
+		remotes[name] = value
+	}
+	return remotes, nil
+}
+
+// remoteURLsArgs returns the options of the git config that lists the remote URLs
+func remoteURLsArgs(dir string) []types.Option {
+	return []types.Option{global.UpperC(dir), config.Local, config.GetRegexp(`^remote\..*\.url$`, "")}
+}
+
+// sameRepository reports whether both URLs have the same host and path, ignoring the
+// scheme, user, ".git" suffix and case
+func sameRepository(a, b *url.URL) bool {
+	pa, errA := repositoryPath(a)
+	pb, errB := repositoryPath(b)
+	return errA == nil && errB == nil && strings.EqualFold(pa, pb)
+}
+
+// SanitizedURL returns the URL without its user information or ref
+func SanitizedURL(u *url.URL) string {
+	s := *u
+	s.User = nil
+	s.Fragment = ""
+	s.RawFragment = ""
+	return s.String()
+}
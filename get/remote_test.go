@@ -0,0 +1,92 @@
+package get
+
+import (
+	"errors"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneExistingRemote(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+	fork, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		url          *url.URL
+		addRemote    string
+		wantMismatch bool
+		wantRemote   string
+	}{
+		"same url": {
+			url: remote,
+		},
+		"same repository in another form": {
+			url: &url.URL{Scheme: "ssh", User: url.User("git"), Path: remote.Path + ".git"},
+		},
+		"different repository": {
+			url:          fork,
+			wantMismatch: true,
+		},
+		"different repository added as remote": {
+			url:        fork,
+			addRemote:  "fork",
+			wantRemote: "fork",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+			if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+
+			_, err := Clone(c.url, dir, CloneOptions{AddRemote: c.addRemote})
+			var mismatch *RemoteMismatchError
+			if c.wantMismatch {
+				if !errors.As(err, &mismatch) {
+					t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): *RemoteMismatchError", err)
+				}
+				if mismatch.Remotes["origin"] != remote.String() {
+					t.Fatalf("unexpected remotes in error: %v", mismatch.Remotes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+
+			if c.wantRemote != "" {
+				if got := gitCmd(t, dir, "remote", "get-url", c.wantRemote); got != c.url.String() {
+					t.Fatalf("unexpected remote url:\n\t(GOT): %s\n\t(WNT): %s", got, c.url.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRemoteURLs(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "--quiet")
+
+	remotes, err := remoteURLs(dir)
+	if err != nil || len(remotes) != 0 {
+		t.Fatalf("unexpected remotes:\n\t(GOT): %v %v\n\t(WNT): map[] <nil>", remotes, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := remoteURLs(dir); !errors.Is(err, exec.ErrNotFound) {
+		t.Fatalf("unexpected error:\n\t(GOT): %v\n\t(WNT): %v", err, exec.ErrNotFound)
+	}
+}
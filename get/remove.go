@@ -0,0 +1,94 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RemoveOptions configures how a repository is removed
+type RemoveOptions struct {
+	// Force removes the repository even if it holds work that would be lost
+	Force bool
+}
+
+// UnsavedWorkError is returned by Remove when the repository holds work that would be lost
+type UnsavedWorkError struct {
+	Dir string
+	// Reasons describes each kind of unsaved work, eg: "2 stashes"
+	Reasons []string
+}
+
+func (e *UnsavedWorkError) Error() string {
+	return fmt.Sprintf("%s has %s", e.Dir, strings.Join(e.Reasons, ", "))
+}
+
+// Remove deletes the repository at dir, then removes its parent directories up to the
+// GETPATH that holds it while they are empty. Unless Force is set, an *UnsavedWorkError is
+// returned instead if the repository has uncommitted changes, untracked files, stashes or
+// commits that are not on any remote. An *UnsafeDirectoryError is returned if dir is not
+// within GETPATH.
+func Remove(dir string, opts RemoveOptions) error {
+	paths, err := roots()
+	if err != nil {
+		return fmt.Errorf("resolving GETPATH: %w", err)
+	}
+	// As with Clone, a repository held by another GETPATH entry is used in place of dir
+	dir = existingDir(paths, dir)
+
+	root := rootOf(paths, dir)
+	if root == "" {
+		return &UnsafeDirectoryError{Dir: dir, Reason: fmt.Sprintf("not within GETPATH %s", strings.Join(paths, string(os.PathListSeparator)))}
+	}
+	if !isGitRepository(dir) {
+		return fmt.Errorf("%w: %s", ErrNotARepository, dir)
+	}
+
+	if !opts.Force {
+		reasons, err := unsavedWork(dir)
+		if err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return &UnsavedWorkError{Dir: dir, Reasons: reasons}
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing repository: %w", err)
+	}
+	for p := filepath.Dir(dir); within(root, p); p = filepath.Dir(p) {
+		if os.Remove(p) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// unsavedWork describes the work in the repository at dir that is not on any remote
+func unsavedWork(dir string) ([]string, error) {
+	r, err := RepositoryReport(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reasons []string
+	for _, c := range []struct {
+		n                int
+		singular, plural string
+	}{
+		{r.Changed, "uncommitted change", "uncommitted changes"},
+		{r.Untracked, "untracked file", "untracked files"},
+		{r.Stashes, "stash", "stashes"},
+		{r.Unpushed, "unpushed commit", "unpushed commits"},
+	} {
+		switch {
+		case c.n == 1:
+			reasons = append(reasons, "1 "+c.singular)
+		case c.n > 1:
+			reasons = append(reasons, fmt.Sprintf("%d %s", c.n, c.plural))
+		}
+	}
+	return reasons, nil
+}
@@ -0,0 +1,154 @@
+package get
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		setup       func(t *testing.T, dir string)
+		force       bool
+		wantReasons []string
+	}{
+		"clean": {},
+		"untracked file": {
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0644); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			},
+			wantReasons: []string{"1 untracked file"},
+		},
+		"uncommitted changes": {
+			setup: func(t *testing.T, dir string) {
+				for _, f := range []string{"a.txt", "b.txt"} {
+					if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+						t.Fatalf("setup: %v", err)
+					}
+				}
+				gitCmd(t, dir, "add", ".")
+			},
+			wantReasons: []string{"2 uncommitted changes"},
+		},
+		"stash": {
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0644); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				gitCmd(t, dir, "stash", "push", "--quiet", "--include-untracked")
+			},
+			wantReasons: []string{"1 stash"},
+		},
+		"unpushed commit": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "local")
+			},
+			wantReasons: []string{"1 unpushed commit"},
+		},
+		"unpushed branch": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "checkout", "--quiet", "-b", "local")
+				gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "local")
+				gitCmd(t, dir, "checkout", "--quiet", "main")
+			},
+			wantReasons: []string{"1 unpushed commit"},
+		},
+		"force": {
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "new.txt"), nil, 0644); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "local")
+			},
+			force: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "local", "owner", "repo")
+			sibling := filepath.Join(getpath, "local", "other", "repo")
+			for _, d := range []string{dir, sibling} {
+				if _, err := Clone(remote, d, CloneOptions{}); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+			if c.setup != nil {
+				c.setup(t, dir)
+			}
+
+			err := Remove(dir, RemoveOptions{Force: c.force})
+			if c.wantReasons != nil {
+				var unsaved *UnsavedWorkError
+				if !errors.As(err, &unsaved) {
+					t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): *UnsavedWorkError", err)
+				}
+				if !slices.Equal(unsaved.Reasons, c.wantReasons) {
+					t.Fatalf("unexpected reasons:\n\t(GOT): %v\n\t(WNT): %v", unsaved.Reasons, c.wantReasons)
+				}
+				if !isGitRepository(dir) {
+					t.Fatalf("expected %s to be kept", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(getpath, "local", "owner")); !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("expected empty parent to be removed, got: %v", err)
+			}
+			if !isGitRepository(sibling) {
+				t.Fatalf("expected %s to be kept", sibling)
+			}
+		})
+	}
+
+	t.Run("last repository prunes up to GETPATH", func(t *testing.T) {
+		getpath := t.TempDir()
+		t.Setenv("GETPATH", getpath)
+		dir := filepath.Join(getpath, "local", "owner", "repo")
+		if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+
+		if err := Remove(dir, RemoveOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries, err := os.ReadDir(getpath)
+		if err != nil {
+			t.Fatalf("expected GETPATH to be kept, got: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("expected GETPATH to be empty, got: %v", entries)
+		}
+	})
+
+	t.Run("outside GETPATH", func(t *testing.T) {
+		t.Setenv("GETPATH", t.TempDir())
+		outside := t.TempDir()
+		gitCmd(t, outside, "init", "--quiet")
+
+		err := Remove(outside, RemoveOptions{Force: true})
+		var unsafe *UnsafeDirectoryError
+		if !errors.As(err, &unsafe) {
+			t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): *UnsafeDirectoryError", err)
+		}
+		if !isGitRepository(outside) {
+			t.Fatalf("expected %s to be kept", outside)
+		}
+	})
+}
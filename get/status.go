@@ -0,0 +1,129 @@
+package get
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/stash"
+	"github.com/ldez/go-git-cmd-wrapper/v2/status"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// Report is the detailed state of a repository, such as whether it holds work that is not
+// on any remote
+type Report struct {
+	// Branch is the current branch, or empty if HEAD is detached
+	Branch string `json:"branch"`
+	// Upstream is the branch's upstream, eg: origin/main, or empty if it has none
+	Upstream string `json:"upstream,omitempty"`
+	// Ahead and Behind are the number of commits the branch is ahead of and behind its upstream
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+	// Changed is the number of files with uncommitted changes, staged or not
+	Changed int `json:"changed"`
+	// Untracked is the number of untracked files
+	Untracked int `json:"untracked"`
+	// Stashes is the number of stash entries
+	Stashes int `json:"stashes"`
+	// Unpushed is the number of commits on any local branch that are not on any remote
+	Unpushed int `json:"unpushed"`
+	// LastCommit is the commit time of HEAD, or the zero time if there are no commits
+	LastCommit time.Time `json:"lastCommit"`
+}
+
+// Dirty reports whether the working tree has uncommitted changes or untracked files
+func (r Report) Dirty() bool {
+	return r.Changed > 0 || r.Untracked > 0
+}
+
+// HasUnpushed reports whether any local branch has commits that are not on a remote
+func (r Report) HasUnpushed() bool {
+	return r.Ahead > 0 || r.Unpushed > 0
+}
+
+// RepositoryReport returns the detailed state of the repository at dir
+func RepositoryReport(dir string) (Report, error) {
+	var r Report
+
+	out, err := git.Status(global.UpperC(dir), status.Porcelain("v2"), status.Branch)
+	if err != nil {
+		return Report{}, gitError("git status", out, err)
+	}
+	for line := range strings.Lines(out) {
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				r.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			r.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// Formatted as "+<ahead> -<behind>"
+			ahead, behind, _ := strings.Cut(strings.TrimPrefix(line, "# branch.ab "), " ")
+			r.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+			r.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+		case strings.HasPrefix(line, "? "):
+			r.Untracked++
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			r.Changed++
+		}
+	}
+
+	if r.Stashes, err = stashCount(dir); err != nil {
+		return Report{}, err
+	}
+	if r.Unpushed, err = unpushedCount(dir); err != nil {
+		return Report{}, err
+	}
+
+	// HEAD does not exist until the first commit
+	out, err = git.Raw("log", global.UpperC(dir), func(g *types.Cmd) {
+		g.AddOptions("-1")
+		g.AddOptions("--format=%ct")
+	})
+	if err == nil {
+		if ts, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64); err == nil {
+			r.LastCommit = time.Unix(ts, 0)
+		}
+	}
+	return r, nil
+}
+
+// stashCount returns the number of stashes in the repository at dir
+func stashCount(dir string) (int, error) {
+	out, err := git.Stash(global.UpperC(dir), stash.List())
+	if err != nil {
+		return 0, gitError("git stash", out, err)
+	}
+	var n int
+	for line := range strings.Lines(out) {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// unpushedCount returns the number of commits on local branches of the repository at dir
+// that are not on any remote-tracking branch
+func unpushedCount(dir string) (int, error) {
+	out, err := git.Raw("rev-list", global.UpperC(dir), func(g *types.Cmd) {
+		g.AddOptions("--count")
+		g.AddOptions("--branches")
+		g.AddOptions("--not")
+		g.AddOptions("--remotes")
+	})
+	if err != nil {
+		return 0, gitError("git rev-list", out, err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: unexpected output %q", out)
+	}
+	return n, nil
+}
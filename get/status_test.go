@@ -0,0 +1,110 @@
+package get
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepositoryReport(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	cases := map[string]struct {
+		setup         func(t *testing.T, dir string)
+		want          Report
+		wantNoCommits bool
+	}{
+		"clean": {
+			want: Report{Branch: "main", Upstream: "origin/main"},
+		},
+		"ahead": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "local")
+			},
+			want: Report{Branch: "main", Upstream: "origin/main", Ahead: 1, Unpushed: 1},
+		},
+		"behind": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "reset", "--quiet", "--hard", "HEAD~1")
+			},
+			want: Report{Branch: "main", Upstream: "origin/main", Behind: 1},
+		},
+		"dirty": {
+			setup: func(t *testing.T, dir string) {
+				for _, f := range []string{"a.txt", "b.txt"} {
+					if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+						t.Fatalf("setup: %v", err)
+					}
+				}
+				gitCmd(t, dir, "add", "a.txt")
+			},
+			want: Report{Branch: "main", Upstream: "origin/main", Changed: 1, Untracked: 1},
+		},
+		"stash": {
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0644); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				gitCmd(t, dir, "stash", "push", "--quiet", "--include-untracked")
+			},
+			want: Report{Branch: "main", Upstream: "origin/main", Stashes: 1},
+		},
+		"unpushed branch without upstream": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "checkout", "--quiet", "-b", "local")
+				gitCmd(t, dir, "commit", "--quiet", "--allow-empty", "-m", "local")
+			},
+			want: Report{Branch: "local", Unpushed: 1},
+		},
+		"detached": {
+			setup: func(t *testing.T, dir string) {
+				gitCmd(t, dir, "checkout", "--quiet", "--detach")
+			},
+			want: Report{},
+		},
+		"no commits": {
+			setup: func(t *testing.T, dir string) {
+				if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+				gitCmd(t, dir, "init", "--quiet", "--initial-branch=main")
+			},
+			want:          Report{Branch: "main"},
+			wantNoCommits: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+			if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			if c.setup != nil {
+				c.setup(t, dir)
+			}
+
+			got, err := RepositoryReport(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.wantNoCommits != got.LastCommit.IsZero() {
+				t.Fatalf("unexpected last commit: %v", got.LastCommit)
+			}
+			if !got.LastCommit.IsZero() && time.Since(got.LastCommit) > time.Hour {
+				t.Fatalf("unexpected last commit: %v", got.LastCommit)
+			}
+			got.LastCommit = time.Time{}
+			if got != c.want {
+				t.Fatalf("unexpected report:\n\t(GOT): %#v\n\t(WNT): %#v", got, c.want)
+			}
+		})
+	}
+}
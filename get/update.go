@@ -0,0 +1,63 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/fetch"
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/pull"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// UpdateOptions configures how a repository is updated
+type UpdateOptions struct {
+	// Pull fast-forwards the current branch after fetching instead of only fetching
+	Pull bool
+}
+
+// Update fetches the repository at dir from its remotes, pruning deleted remote branches.
+// With Pull set, the current branch is also fast-forwarded to its upstream.
+func Update(dir string, opts UpdateOptions) error {
+	return UpdateContext(context.Background(), dir, opts)
+}
+
+// UpdateContext is Update with a context that kills git when done
+func UpdateContext(ctx context.Context, dir string, opts UpdateOptions) error {
+	if opts.Pull {
+		out, err := git.PullWithContext(ctx, global.UpperC(dir), pull.Quiet, pull.FfOnly, func(g *types.Cmd) {
+			g.AddOptions("--prune")
+		})
+		if err != nil {
+			return gitError("git pull", out, err)
+		}
+		return nil
+	}
+
+	out, err := git.FetchWithContext(ctx, global.UpperC(dir), fetch.Quiet, fetch.Prune, fetch.All)
+	if err != nil {
+		return gitError("git fetch", out, err)
+	}
+	return nil
+}
+
+// gitError wraps err from a git command with the line of its output that best describes
+// what went wrong: the first fatal:, error: or remote: line, or else the last line, as git
+// often follows the cause with hints
+func gitError(cmd, out string, err error) error {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	msg := strings.TrimSpace(lines[len(lines)-1])
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") || strings.HasPrefix(line, "remote:") {
+			msg = line
+			break
+		}
+	}
+	if msg != "" {
+		return fmt.Errorf("%s: %w: %s", cmd, err, msg)
+	}
+	return fmt.Errorf("%s: %w", cmd, err)
+}
@@ -0,0 +1,63 @@
+package get
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	cases := map[string]struct {
+		opts     UpdateOptions
+		wantHead bool
+	}{
+		"fetch": {
+			opts: UpdateOptions{},
+		},
+		"pull": {
+			opts:     UpdateOptions{Pull: true},
+			wantHead: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			remote, _ := fixtureRepo(t)
+			getpath := t.TempDir()
+			t.Setenv("GETPATH", getpath)
+			dir := filepath.Join(getpath, "repo")
+			if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+			head := gitCmd(t, dir, "rev-parse", "HEAD")
+
+			upstream := filepath.FromSlash(remote.Path)
+			gitCmd(t, upstream, "commit", "--quiet", "--allow-empty", "-m", "fourth")
+			want := gitCmd(t, upstream, "rev-parse", "HEAD")
+
+			if err := Update(dir, c.opts); err != nil {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			}
+
+			if got := gitCmd(t, dir, "rev-parse", "origin/main"); got != want {
+				t.Fatalf("unexpected origin/main:\n\t(GOT): %s\n\t(WNT): %s", got, want)
+			}
+			if c.wantHead {
+				head = want
+			}
+			if got := gitCmd(t, dir, "rev-parse", "HEAD"); got != head {
+				t.Fatalf("unexpected HEAD:\n\t(GOT): %s\n\t(WNT): %s", got, head)
+			}
+		})
+	}
+
+	t.Run("not a repository", func(t *testing.T) {
+		if err := Update(t.TempDir(), UpdateOptions{}); err == nil {
+			t.Fatal("expected error:\n\t(GOT): nil")
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package get
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ldez/go-git-cmd-wrapper/v2/git"
+	"github.com/ldez/go-git-cmd-wrapper/v2/global"
+	"github.com/ldez/go-git-cmd-wrapper/v2/remote"
+	"github.com/ldez/go-git-cmd-wrapper/v2/status"
+	"github.com/ldez/go-git-cmd-wrapper/v2/types"
+)
+
+// Repository is a git repository found under GETPATH
+type Repository struct {
+	// Name is the path relative to GETPATH with forward slashes, eg: github.com/arbourd/git-get
+	Name string `json:"name"`
+	// Path is the absolute path to the repository
+	Path string `json:"path"`
+}
+
+// Status is the state of a repository's working tree
+type Status struct {
+	// Remote is the URL of the origin remote, or empty if there is none
+	Remote string `json:"remote"`
+	// Branch is the current branch, or empty if HEAD is detached
+	Branch string `json:"branch"`
+	// Dirty reports whether there are uncommitted changes or untracked files
+	Dirty bool `json:"dirty"`
+}
+
+// Walk calls fn for every repository under root in lexical order.
+// Repositories are not descended into, so nested repositories such as vendored
+// dependencies are skipped, as are directories starting with ".".
+// A root that does not exist contains no repositories.
+func Walk(root string, fn func(Repository) error) error {
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if !isGitRepository(p) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		if err := fn(Repository{Name: filepath.ToSlash(rel), Path: p}); err != nil {
+			return err
+		}
+		return fs.SkipDir
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// List returns the repositories under GETPATH whose name matches any of the patterns.
+// All repositories are returned when no patterns are given.
+func List(patterns ...string) ([]Repository, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	paths, err := roots()
+	if err != nil {
+		return nil, fmt.Errorf("resolving GETPATH: %w", err)
+	}
+
+	var repos []Repository
+	seen := make(map[string]bool)
+	for _, getpath := range paths {
+		err = Walk(getpath, func(r Repository) error {
+			// Roots may be nested within each other, so skip repositories already found
+			if seen[r.Path] {
+				return nil
+			}
+			seen[r.Path] = true
+			if len(patterns) == 0 || matchAny(patterns, r.Name) {
+				repos = append(repos, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// Match reports whether the repository name matches the glob pattern, case-insensitively.
+// The pattern is matched against as many leading path segments as it has, so "github.com"
+// matches every repository on that host and "*/arbourd" matches an owner on any host.
+func Match(pattern, name string) bool {
+	pattern = strings.ToLower(strings.Trim(pattern, "/"))
+	segments := strings.Split(strings.ToLower(name), "/")
+
+	n := strings.Count(pattern, "/") + 1
+	if n > len(segments) {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(segments[:n], "/"))
+	return ok
+}
+
+// matchAny reports whether the repository name matches any of the patterns
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepositoryStatus returns the remote, branch and working tree state of the repository at dir
+func RepositoryStatus(dir string) (Status, error) {
+	var s Status
+
+	out, err := git.Remote(global.UpperC(dir), remote.GetURL("origin"))
+	if err == nil {
+		s.Remote = strings.TrimSpace(out)
+	}
+
+	out, err = git.Branch(global.UpperC(dir), func(g *types.Cmd) {
+		g.AddOptions("--show-current")
+	})
+	if err != nil {
+		return Status{}, fmt.Errorf("git branch: %w", err)
+	}
+	s.Branch = strings.TrimSpace(out)
+
+	out, err = git.Status(global.UpperC(dir), status.Porcelain("v1"))
+	if err != nil {
+		return Status{}, fmt.Errorf("git status: %w", err)
+	}
+	s.Dirty = strings.TrimSpace(out) != ""
+	return s, nil
+}
+
+// Lookup finds an existing repository by its path relative to any GETPATH root, as returned by Complete
+// and List, optionally followed by @ref. It returns the URL of the repository's origin remote,
+// with the ref as its fragment, and the repository's directory. The URL is nil if there is
+// no repository at the path, or if the name is a URL with a scheme or SCP-like syntax. This
+// lets repositories be named by directory under any layout.
+func Lookup(name string) (*url.URL, string, error) {
+	if isRemoteURL(name) {
+		return nil, "", nil
+	}
+	p := &url.URL{Path: name}
+	if err := splitRef(p); err != nil {
+		return nil, "", err
+	}
+
+	dir, err := LookupDirectory(p.Path)
+	if err != nil || dir == "" {
+		return nil, "", err
+	}
+
+	remotes, err := remoteURLs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	origin, ok := remotes["origin"]
+	if !ok {
+		return nil, "", fmt.Errorf("%s has no origin remote", dir)
+	}
+	u, err := ParseURL(origin)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsing origin of %s: %w", dir, err)
+	}
+	u.Fragment = p.Fragment
+	return u, dir, nil
+}
+
+// LookupDirectory returns the directory of an existing repository by its path relative to
+// any GETPATH root, without a ref. It returns an empty string if there is no repository at
+// the path, or if the path is a URL with a scheme or SCP-like syntax. Unlike Lookup, the
+// repository's remotes are not read.
+func LookupDirectory(path string) (string, error) {
+	rel := filepath.FromSlash(strings.TrimSuffix(path, "/"))
+	if isRemoteURL(path) || !filepath.IsLocal(rel) {
+		return "", nil
+	}
+
+	paths, err := roots()
+	if err != nil {
+		return "", fmt.Errorf("resolving GETPATH: %w", err)
+	}
+	i := slices.IndexFunc(paths, func(getpath string) bool {
+		return isGitRepository(filepath.Join(getpath, rel))
+	})
+	if i < 0 {
+		return "", nil
+	}
+	return filepath.Join(paths[i], rel), nil
+}
+
+// isRemoteURL reports whether the name is a URL with a scheme or SCP-like syntax, rather than
+// a path
+func isRemoteURL(name string) bool {
+	return strings.Contains(name, "://") || scpSyntaxRe.MatchString(name)
+}
@@ -0,0 +1,216 @@
+package get
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+
+	getpath := t.TempDir()
+	repos := []string{
+		"github.com/arbourd/git-get",
+		"github.com/arbourd/git-get/vendor/nested",
+		"github.com/torvalds/linux",
+		"gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection",
+		".cache/github.com/arbourd/git-get",
+	}
+	for _, r := range repos {
+		dir := filepath.Join(getpath, filepath.FromSlash(r))
+		if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	t.Setenv("GETPATH", getpath)
+
+	cases := map[string]struct {
+		patterns []string
+		want     []string
+		wantErr  bool
+	}{
+		"all": {
+			want: []string{
+				"github.com/arbourd/git-get",
+				"github.com/torvalds/linux",
+				"gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection",
+			},
+		},
+		"host": {
+			patterns: []string{"github.com"},
+			want:     []string{"github.com/arbourd/git-get", "github.com/torvalds/linux"},
+		},
+		"owner glob": {
+			patterns: []string{"*/Arbourd"},
+			want:     []string{"github.com/arbourd/git-get"},
+		},
+		"repository glob": {
+			patterns: []string{"gitlab.com/*/*/ai-*"},
+			want:     []string{"gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection"},
+		},
+		"multiple patterns": {
+			patterns: []string{"github.com/torvalds", "gitlab.com"},
+			want:     []string{"github.com/torvalds/linux", "gitlab.com/gitlab-org/dev-subdepartment/ai-dev-promptcollection"},
+		},
+		"no match": {
+			patterns: []string{"bitbucket.org"},
+			want:     nil,
+		},
+		"invalid pattern": {
+			patterns: []string{"github.com/["},
+			wantErr:  true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := List(c.patterns...)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): nil\n")
+			}
+
+			var names []string
+			for _, r := range got {
+				if r.Path != filepath.Join(getpath, filepath.FromSlash(r.Name)) {
+					t.Fatalf("unexpected path %q for %q", r.Path, r.Name)
+				}
+				names = append(names, r.Name)
+			}
+			if !slices.Equal(names, c.want) {
+				t.Fatalf("unexpected repositories:\n\t(GOT): %v\n\t(WNT): %v", names, c.want)
+			}
+		})
+	}
+
+	t.Run("non-existent GETPATH returns empty", func(t *testing.T) {
+		t.Setenv("GETPATH", filepath.Join(t.TempDir(), "nonexistent"))
+
+		got, err := List()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("expected empty, got: %v", got)
+		}
+	})
+}
+
+func TestRepositoryStatus(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+	dir := filepath.Join(getpath, "repo")
+	if _, err := Clone(remote, dir, CloneOptions{}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	s, err := RepositoryStatus(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Status{Remote: remote.String(), Branch: "main"}
+	if s != want {
+		t.Fatalf("unexpected status:\n\t(GOT): %#v\n\t(WNT): %#v", s, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "untracked"), nil, 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	gitCmd(t, dir, "checkout", "--quiet", "--detach")
+
+	s, err = RepositoryStatus(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = Status{Remote: remote.String(), Dirty: true}
+	if s != want {
+		t.Fatalf("unexpected status:\n\t(GOT): %#v\n\t(WNT): %#v", s, want)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	err := gitConfigGlobalFixture(t)
+	if err != nil {
+		t.Fatalf("unable to setup test fixture: %s", err)
+	}
+	remote, _ := fixtureRepo(t)
+
+	getpath := t.TempDir()
+	t.Setenv("GETPATH", getpath)
+	if _, err := Clone(remote, filepath.Join(getpath, "git-get"), CloneOptions{}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	gitCmd(t, getpath, "init", "--quiet", "no-remote")
+	// Repositories that URLs would name if they were taken as paths
+	gitCmd(t, getpath, "init", "--quiet", "git")
+	gitCmd(t, getpath, "init", "--quiet", "https:/example.com/foo/bar")
+
+	cases := map[string]struct {
+		name    string
+		wantURL string
+		wantDir string
+		wantErr bool
+	}{
+		"repository": {
+			name:    "git-get",
+			wantURL: remote.String(),
+			wantDir: filepath.Join(getpath, "git-get"),
+		},
+		"repository with ref": {
+			name:    "git-get/@v1",
+			wantURL: remote.String() + "#v1",
+			wantDir: filepath.Join(getpath, "git-get"),
+		},
+		"not a repository": {
+			name: "missing",
+		},
+		"outside GETPATH": {
+			name: "../git-get",
+		},
+		"no origin": {
+			name:    "no-remote",
+			wantErr: true,
+		},
+		"empty ref": {
+			name:    "git-get@",
+			wantErr: true,
+		},
+		"scp url": {
+			name: "git@example.com:foo/bar",
+		},
+		"scheme url": {
+			name: "https://example.com/foo/bar",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			u, dir, err := Lookup(c.name)
+			if err != nil && !c.wantErr {
+				t.Fatalf("unexpected error:\n\t(GOT): %#v\n\t(WNT): nil", err)
+			} else if err == nil && c.wantErr {
+				t.Fatalf("expected error:\n\t(GOT): nil\n")
+			}
+
+			var gotURL string
+			if u != nil {
+				gotURL = u.String()
+			}
+			if gotURL != c.wantURL || dir != c.wantDir {
+				t.Fatalf("unexpected lookup:\n\t(GOT): %q %q\n\t(WNT): %q %q", gotURL, dir, c.wantURL, c.wantDir)
+			}
+		})
+	}
+}
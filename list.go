@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const listUsage = `Usage: git-get list [options]
+
+List repositories in GETPATH.
+
+Options:
+  --filter <glob>  Only list repositories matching the glob, eg: github.com/arbourd
+                   May be given multiple times
+  --short          Print paths relative to the home directory
+  --json           Print JSON including the remote, branch and dirty state
+  -h, --help       Show this help message`
+
+// listEntry is a repository and its status as printed by list --json
+type listEntry struct {
+	get.Repository
+	get.Status
+}
+
+func list(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("list")
+	var filters stringList
+	fs.Var(&filters, "filter", "")
+	short := fs.Bool("short", false, "")
+	asJSON := fs.Bool("json", false, "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, listUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	repos, err := get.List(filters...)
+	if err != nil {
+		return fmt.Errorf("listing repositories: %w", err)
+	}
+
+	if *asJSON {
+		// A repository that cannot be read is reported without hiding the others
+		entries := make([]listEntry, 0, len(repos))
+		var failed int
+		for _, r := range repos {
+			s, err := get.RepositoryStatus(r.Path)
+			if err != nil {
+				failed++
+				fmt.Fprintf(stderr, "failed  %s: %s\n", r.Name, err)
+				continue
+			}
+			entries = append(entries, listEntry{Repository: r, Status: s})
+		}
+
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d repositories could not be read", failed, len(repos))
+		}
+		return nil
+	}
+
+	for _, r := range repos {
+		if *short {
+			fmt.Fprintln(stdout, get.ShortPath(r.Path))
+		} else {
+			fmt.Fprintln(stdout, r.Path)
+		}
+	}
+	return nil
+}
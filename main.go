@@ -1,10 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/arbourd/git-get/get"
 )
@@ -12,25 +23,88 @@ import (
 // Version is set via -ldflags at build time.
 var Version = "dev"
 
-const usage = `Usage: git-get <repository>
+const usage = `Usage: git-get [options] <repository>...
+       git-get <command> [options]
 
-Clone a git repository to GETPATH (%s).
+Clone git repositories to GETPATH (%s).
 
 Arguments:
-  repository  The git repository URL to clone
+  repository  The git repository URL to clone, or - to read URLs from stdin
+
+Commands:
+  list        List repositories in GETPATH
+  find        Print the repository that best matches a partial name
+  status      Show unsaved work in every repository in GETPATH
+  update      Fetch every repository in GETPATH
+  foreach     Run a command in every repository in GETPATH
+  rm          Remove a repository from GETPATH
+  export      Print a manifest of the repositories in GETPATH
+  import      Clone the repositories in a manifest
+  shell-init  Print a shell function that changes to the cloned repository
 
 Options:
-  -h, --help     Show this help message
-  -v, --version  Show version`
+  --depth <n>             Create a shallow clone with the last n commits
+  --shallow-since <date>  Create a shallow clone with commits newer than date
+  --filter <spec>         Create a partial clone, eg: blob:none or tree:0
+  --add-remote <name>     Add the URL as a remote to an existing repository whose
+                          remotes do not match it, instead of failing
+  --cache                 Borrow objects from a mirror kept in GETPATH/.cache,
+                          creating or fetching it first
+  --dissociate            With --cache, copy the borrowed objects so the clone does
+                          not depend on the cache
+  --backend <name>        Clone and check repositories with exec (git) or go-git
+  -j, --jobs <n>          Clone n repositories at a time (default 8)
+  --timeout <d>           Stop cloning a repository after d, eg: 30s or 2m
+  --json                  Print the url, directory, shortPath, status (cloned, existing
+                          or failed), duration and error of each repository as JSON
+  --format <template>     Print each repository with a Go template of the same fields,
+                          eg: '{{.Status}} {{.Directory}}'
+  -n, --dry-run           Print the URL, directory and git commands of each repository
+                          without cloning it
+  --print-only            Print the directory without changing to it, when wrapped
+                          by the shell-init function
+  -h, --help              Show this help message
+  -v, --version           Show version`
+
+// Exit statuses for errors from the get package, so scripts can tell failures apart.
+// Any other error exits with status 1.
+const (
+	exitRemoteNotFound      = 3
+	exitAuthFailed          = 4
+	exitDestinationOccupied = 5
+	exitNotARepository      = 6
+	exitOutsideGetpath      = 7
+	// exitInterrupted is the status shells use for a command killed by SIGINT
+	exitInterrupted = 130
+)
 
 func main() {
-	if err := run(os.Args[1:], os.Stdout); err != nil {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func run(args []string, stdout io.Writer) error {
+// exitCode returns the exit status for an error returned by run
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, get.ErrRemoteNotFound):
+		return exitRemoteNotFound
+	case errors.Is(err, get.ErrAuthFailed):
+		return exitAuthFailed
+	case errors.Is(err, get.ErrDestinationOccupied):
+		return exitDestinationOccupied
+	case errors.Is(err, get.ErrNotARepository):
+		return exitNotARepository
+	case errors.Is(err, get.ErrOutsideGetpath):
+		return exitOutsideGetpath
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	}
+	return 1
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	if len(args) == 0 {
 		return fmt.Errorf("no repository specified\n\n%s", buildUsage())
 	}
@@ -56,37 +130,381 @@ func run(args []string, stdout io.Writer) error {
 			fmt.Fprintln(stdout, m)
 		}
 		return nil
+	case "list":
+		return list(args[1:], stdout, stderr)
+	case "update":
+		return update(args[1:], stdout)
+	case "status":
+		return status(args[1:], stdout, stderr)
+	case "foreach":
+		return foreach(args[1:], stdout, stderr)
+	case "export":
+		return export(args[1:], stdout)
+	case "import":
+		return importManifest(args[1:], stdin, stdout)
+	case "find":
+		return find(args[1:], stdout)
+	case "rm":
+		return rm(args[1:], stdout)
+	case "shell-init":
+		return shellInit(args[1:], stdout)
 	}
 
-	return clone(args[0], stdout)
+	return clone(args, stdin, stdout, stderr)
 }
 
-func clone(remote string, stdout io.Writer) error {
-	path, err := get.AbsolutePath()
+func clone(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("git-get")
+	depth := fs.Int("depth", 0, "")
+	shallowSince := fs.String("shallow-since", "", "")
+	filter := fs.String("filter", "", "")
+	addRemote := fs.String("add-remote", "", "")
+	cache := fs.Bool("cache", false, "")
+	dissociate := fs.Bool("dissociate", false, "")
+	backendName := fs.String("backend", "", "")
+	jobs := jobsFlag(fs)
+	timeout := fs.Duration("timeout", 0, "")
+	asJSON := fs.Bool("json", false, "")
+	format := fs.String("format", "", "")
+	dryRun := fs.Bool("dry-run", false, "")
+	fs.BoolVar(dryRun, "n", false, "")
+	// Handled by the shell-init function; the directory is always printed
+	fs.Bool("print-only", false, "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, buildUsage())
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("resolving GETPATH: %w", err)
+		return err
+	}
+	if *depth < 0 {
+		return fmt.Errorf("invalid depth: %d", *depth)
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid jobs: %d", *jobs)
+	}
+	if *timeout < 0 {
+		return fmt.Errorf("invalid timeout: %s", *timeout)
+	}
+	var backend get.Backend
+	if *backendName != "" {
+		if backend, err = get.BackendByName(*backendName); err != nil {
+			return fmt.Errorf("invalid backend: %w", err)
+		}
+	}
+	if *asJSON && *format != "" {
+		return errors.New("--json and --format cannot be used together")
+	}
+	if *dryRun && (*asJSON || *format != "") {
+		return errors.New("--dry-run cannot be used with --json or --format")
+	}
+	var tmpl *template.Template
+	if *format != "" {
+		tmpl, err = template.New("format").Parse(*format)
+		if err != nil {
+			return fmt.Errorf("invalid format: %w", err)
+		}
 	}
 
-	url, err := get.ParseURL(remote)
+	remotes, err := readRemotes(positional, stdin)
 	if err != nil {
-		return fmt.Errorf("unable to parse repository url %q: %w", remote, err)
+		return err
+	}
+	if len(remotes) == 0 {
+		return fmt.Errorf("no repository specified\n\n%s", buildUsage())
 	}
+	remotes = uniqueRepositories(remotes)
 
-	relDir, err := get.Directory(url)
+	// Options given on the command line take precedence over the Git config defaults
+	override := func(opts *get.CloneOptions) {
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "depth":
+				opts.Depth = *depth
+			case "shallow-since":
+				opts.ShallowSince = *shallowSince
+			case "filter":
+				opts.Filter = *filter
+			case "add-remote":
+				opts.AddRemote = *addRemote
+			case "cache":
+				opts.Cache = *cache
+			case "dissociate":
+				opts.Dissociate = *dissociate
+			case "backend":
+				opts.Backend = backend
+			}
+		})
+	}
+
+	if *dryRun {
+		return dryRunClone(remotes, override, stdout, stderr)
+	}
+
+	// An interrupt kills the running git commands, which removes their partial clones
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	entries := make([]cloneEntry, len(remotes))
+	errs := make([]error, len(remotes))
+	var mu sync.Mutex
+	var failed int
+	parallel(*jobs, len(remotes), func(i int) {
+		start := time.Now()
+		result, err := cloneRepository(ctx, remotes[i], *timeout, override)
+		entries[i] = newCloneEntry(remotes[i], result, time.Since(start), err)
+		errs[i] = err
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed++
+		}
+		switch {
+		case *asJSON:
+			// Printed together once every repository is done
+		case tmpl != nil:
+			if err := tmpl.Execute(stdout, entries[i]); err != nil {
+				fmt.Fprintf(stderr, "error: %s: formatting: %s\n", remotes[i], err)
+			}
+			fmt.Fprintln(stdout)
+		case err != nil:
+			// A single failure is returned, and printed, by run
+			if len(remotes) > 1 {
+				fmt.Fprintf(stderr, "error: %s: %s\n", remotes[i], err)
+			}
+		default:
+			fmt.Fprintln(stdout, result.Directory)
+		}
+	})
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+	}
+
+	if len(remotes) == 1 {
+		return errs[0]
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed to clone", failed, len(remotes))
+	}
+	return nil
+}
+
+// Statuses of a repository in the output of --json and --format
+const (
+	statusCloned   = "cloned"
+	statusExisting = "existing"
+	statusFailed   = "failed"
+)
+
+// cloneEntry is the outcome of cloning a repository as printed by --json and --format
+type cloneEntry struct {
+	URL       string `json:"url"`
+	Directory string `json:"directory,omitempty"`
+	ShortPath string `json:"shortPath,omitempty"`
+	Status    string `json:"status"`
+	// Duration is the number of seconds spent on the repository
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// newCloneEntry describes the result of cloning the remote, or err if it failed
+func newCloneEntry(remote string, result get.CloneResult, d time.Duration, err error) cloneEntry {
+	e := cloneEntry{Duration: d.Round(time.Millisecond).Seconds()}
+	switch {
+	case err != nil:
+		e.URL, e.Status, e.Error = redactURL(remote), statusFailed, err.Error()
+	case result.Existing:
+		e.URL, e.Status = result.URL, statusExisting
+	default:
+		e.URL, e.Status = result.URL, statusCloned
+	}
+	if result.Directory != "" {
+		e.Directory, e.ShortPath = result.Directory, get.ShortPath(result.Directory)
+	}
+	return e
+}
+
+// redactURL returns the remote without any user information, so that it is safe to print
+func redactURL(remote string) string {
+	if u, err := get.ParseURL(remote); err == nil {
+		return get.SanitizedURL(u)
+	}
+	if u, err := url.Parse(remote); err == nil && u.User != nil {
+		u.User = nil
+		return u.String()
+	}
+	return remote
+}
+
+// cloneRepository clones a single remote to GETPATH. A non-zero timeout limits how long the
+// clone may take.
+func cloneRepository(ctx context.Context, remote string, timeout time.Duration, override func(*get.CloneOptions)) (get.CloneResult, error) {
+	url, dir, err := resolveRepository(remote)
 	if err != nil {
-		return fmt.Errorf("unable to determine directory for url %q: %w", remote, err)
+		return get.CloneResult{}, err
 	}
 
-	dir := filepath.Join(path, relDir)
-	result, err := get.Clone(url, dir)
+	opts, err := get.ConfigCloneOptions(url)
 	if err != nil {
-		return fmt.Errorf("cloning repository: %w", err)
+		return get.CloneResult{}, fmt.Errorf("reading clone options: %w", err)
+	}
+	override(&opts)
+
+	ctx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+	result, err := get.CloneContext(ctx, url, dir, opts)
+	if err != nil {
+		return get.CloneResult{}, fmt.Errorf("cloning repository: %w", err)
+	}
+	return result, nil
+}
+
+// resolveRepository returns the URL of the remote and the directory under GETPATH that it
+// is cloned to. The remote may also name an existing repository by its directory.
+func resolveRepository(remote string) (*url.URL, string, error) {
+	// With layouts other than the default, a directory under GETPATH such as one returned by
+	// completion may not parse back to itself, so an existing repository there is used before
+	// filling in get.defaultHost, or when the remote does not parse at all
+	hostless := get.HasDefaultHost(remote)
+	if hostless {
+		if u, dir, err := get.Lookup(remote); err != nil || u != nil {
+			return u, dir, err
+		}
+	}
+
+	u, dir, err := repositoryDirectory(remote)
+	if err == nil || hostless {
+		return u, dir, err
+	}
+	lookupURL, lookupDir, lookupErr := get.Lookup(remote)
+	if lookupErr != nil {
+		return nil, "", lookupErr
+	}
+	if lookupURL == nil {
+		return nil, "", err
+	}
+	return lookupURL, lookupDir, nil
+}
+
+// repositoryDirectory parses the remote and returns its URL and the directory under
+// GETPATH that it is cloned to
+func repositoryDirectory(remote string) (*url.URL, string, error) {
+	u, err := get.ParseURL(remote)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to parse repository url %q: %w", remote, err)
+	}
+	path, err := get.AbsolutePathFor(u)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving GETPATH: %w", err)
+	}
+	relDir, err := get.Directory(u)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to determine directory for url %q: %w", remote, err)
+	}
+	return u, filepath.Join(path, relDir), nil
+}
+
+// uniqueRepositories returns the remotes without those that resolve to the same directory
+// as an earlier one, such as github.com/a/b and https://github.com/a/b.git, so that they are
+// not cloned into it at the same time. Remotes that do not resolve are kept, to fail later.
+func uniqueRepositories(remotes []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, r := range remotes {
+		_, dir, err := resolveRepository(r)
+		if err == nil && seen[dir] {
+			continue
+		}
+		if err == nil {
+			seen[dir] = true
+		}
+		unique = append(unique, r)
 	}
+	return unique
+}
+
+// readRemotes returns the remotes given as arguments, replacing "-" with the
+// newline-separated remotes read from stdin. Blank lines and duplicates are skipped.
+func readRemotes(args []string, stdin io.Reader) ([]string, error) {
+	var remotes []string
+	seen := make(map[string]bool)
+	add := func(r string) {
+		if r != "" && !seen[r] {
+			seen[r] = true
+			remotes = append(remotes, r)
+		}
+	}
+
+	readStdin := false
+	for _, arg := range args {
+		if arg != "-" {
+			add(arg)
+			continue
+		}
+		if readStdin {
+			continue
+		}
+		readStdin = true
+
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			add(strings.TrimSpace(scanner.Text()))
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading repositories from stdin: %w", err)
+		}
+	}
+	return remotes, nil
+}
 
-	fmt.Fprintln(stdout, result)
+// newFlagSet returns a flag set that reports errors to the caller instead of printing them
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// stringList is a flag that may be given multiple times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
 	return nil
 }
 
+// parseArgs parses flags from args and returns the positional arguments.
+// Unlike flag.FlagSet.Parse, flags may appear after positional arguments; "--" ends flag parsing.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
 func buildUsage() string {
 	path, err := get.AbsolutePath()
 	if err != nil {
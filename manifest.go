@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const exportUsage = `Usage: git-get export [options]
+
+Print a JSON manifest of the repositories in GETPATH.
+
+Options:
+  --filter <glob>  Only export repositories matching the glob, eg: github.com/arbourd
+                   May be given multiple times
+  -h, --help       Show this help message`
+
+const importUsage = `Usage: git-get import [options] <manifest>
+
+Clone the repositories in a manifest that are missing from GETPATH.
+
+Arguments:
+  manifest  The manifest written by export, or - to read it from stdin
+
+Options:
+  -j, --jobs <n>  Clone n repositories at a time (default 8)
+  -h, --help      Show this help message`
+
+func export(args []string, stdout io.Writer) error {
+	fs := newFlagSet("export")
+	var filters stringList
+	fs.Var(&filters, "filter", "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, exportUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	// Repositories that cannot be read are left out of the manifest, and reported after it
+	m, err := get.Export(filters...)
+	if m.Repositories == nil {
+		return fmt.Errorf("exporting repositories: %w", err)
+	}
+	if err := m.Write(stdout); err != nil {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("exporting repositories: %w", err)
+	}
+	return nil
+}
+
+func importManifest(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("import")
+	jobs := jobsFlag(fs)
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, importUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("no manifest specified\n\n%s", importUsage)
+	}
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional[1:], " "))
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid jobs: %d", *jobs)
+	}
+
+	r := stdin
+	if name := positional[0]; name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("opening manifest: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	m, err := get.ReadManifest(r)
+	if err != nil {
+		return err
+	}
+
+	// An interrupt kills the running git commands, which removes their partial clones
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var mu sync.Mutex
+	var cloned, failed int
+	parallel(*jobs, len(m.Repositories), func(i int) {
+		e := m.Repositories[i]
+		_, ok, err := e.ImportContext(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(stdout, "failed  %s: %s\n", e.Directory, err)
+		case ok:
+			cloned++
+			fmt.Fprintf(stdout, "cloned  %s\n", e.Directory)
+		default:
+			fmt.Fprintf(stdout, "exists  %s\n", e.Directory)
+		}
+	})
+
+	total := len(m.Repositories)
+	fmt.Fprintf(stdout, "%d cloned, %d existing, %d failed\n", cloned, total-cloned-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed to import", failed, total)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const rmUsage = `Usage: git-get rm [options] <repository>...
+
+Remove repositories from GETPATH, along with any parent directories left empty.
+A repository may be given as a URL or as a path relative to GETPATH.
+
+Repositories with uncommitted changes, untracked files, stashes or commits that
+are not on any remote are not removed.
+
+Options:
+  -f, --force  Remove repositories even if they hold unsaved work
+  -h, --help   Show this help message`
+
+func rm(args []string, stdout io.Writer) error {
+	fs := newFlagSet("rm")
+	force := fs.Bool("force", false, "")
+	fs.BoolVar(force, "f", false, "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, rmUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("no repository specified\n\n%s", rmUsage)
+	}
+
+	for _, remote := range positional {
+		dir, err := removalDirectory(remote)
+		if err != nil {
+			return err
+		}
+		if err := get.Remove(dir, get.RemoveOptions{Force: *force}); err != nil {
+			var unsaved *get.UnsavedWorkError
+			if errors.As(err, &unsaved) {
+				return fmt.Errorf("%w; use --force to remove it anyway", err)
+			}
+			return fmt.Errorf("removing repository: %w", err)
+		}
+		fmt.Fprintf(stdout, "removed %s\n", dir)
+	}
+	return nil
+}
+
+// removalDirectory returns the directory that the remote is cloned to, or else the
+// repository at the remote's path relative to GETPATH. Unlike resolveRepository, a URL is
+// never taken as a path, and the repository's remotes are not read, so that rm only removes
+// the repository it was given.
+func removalDirectory(remote string) (string, error) {
+	_, dir, err := repositoryDirectory(remote)
+	if err == nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return dir, nil
+		}
+	}
+
+	relDir, lookupErr := get.LookupDirectory(remote)
+	if lookupErr != nil {
+		return "", lookupErr
+	}
+	if relDir != "" {
+		return relDir, nil
+	}
+	// Any missing repository is reported by get.Remove
+	return dir, err
+}
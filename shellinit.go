@@ -0,0 +1,174 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const shellInitUsage = `Usage: git-get shell-init <bash|zsh|fish|pwsh>
+
+Print a shell function that wraps git-get, and git get, to change to the directory
+of the cloned or found repository. The completions for the shell are included.
+
+  bash, zsh  eval "$(git get shell-init bash)"
+  fish       git get shell-init fish | source
+  pwsh       git get shell-init pwsh | Out-String | Invoke-Expression
+
+Pass --print-only to the wrapped command to print the directory without changing to it.
+
+Options:
+  -h, --help  Show this help message`
+
+//go:embed completions
+var completions embed.FS
+
+// passthroughCommands are run by the shell function without changing directory, as they
+// do not print the directory of a single repository
+var passthroughCommands = []string{
+	"list", "update", "status", "foreach", "export", "import", "rm", "shell-init",
+	"--complete", "--help", "-h", "--version", "-v",
+}
+
+// shells holds, for each shell, the completion script and the function that wraps
+// git-get. The function's %s is replaced with the passthrough commands.
+var shells = map[string]struct {
+	completion string
+	wrapper    string
+	commands   func([]string) string
+}{
+	"bash": {
+		completion: "completions/git-get.bash",
+		wrapper:    posixWrapper,
+		commands:   func(c []string) string { return strings.Join(c, "|") },
+	},
+	"zsh": {
+		completion: "completions/git-get.zsh",
+		wrapper:    posixWrapper + "\n(( $+functions[compdef] )) && compdef _git_get git-get\n",
+		commands:   func(c []string) string { return strings.Join(c, "|") },
+	},
+	"fish": {
+		completion: "completions/git-get.fish",
+		wrapper:    fishWrapper,
+		commands:   func(c []string) string { return strings.Join(c, " ") },
+	},
+	"pwsh": {
+		completion: "completions/git-get.ps1",
+		wrapper:    pwshWrapper,
+		commands:   func(c []string) string { return "'" + strings.Join(c, "', '") + "'" },
+	},
+}
+
+const posixWrapper = `
+git-get() {
+    local arg out code
+    case "$1" in
+        %s) command git-get "$@"; return ;;
+    esac
+    for arg in "$@"; do
+        [ "$arg" = "--print-only" ] && { command git-get "$@"; return; }
+    done
+
+    out="$(command git-get "$@")"
+    code=$?
+    [ -n "$out" ] && printf '%%s\n' "$out"
+    if [ $code -eq 0 ] && [ -n "$out" ] && [ "${out%%
+*}" = "$out" ] && [ -d "$out" ]; then
+        cd -- "$out" || return
+    fi
+    return $code
+}
+
+git() {
+    if [ "$1" = "get" ]; then
+        shift
+        git-get "$@"
+    else
+        command git "$@"
+    fi
+}
+`
+
+const fishWrapper = `
+function git-get
+    if contains -- "$argv[1]" %s; or contains -- --print-only $argv
+        command git-get $argv
+        return
+    end
+
+    set -l out (command git-get $argv)
+    set -l code $status
+    test (count $out) -gt 0; and printf '%%s\n' $out
+    if test $code -eq 0; and test (count $out) -eq 1; and test -d "$out[1]"
+        cd $out[1]
+    end
+    return $code
+end
+
+function git
+    if test "$argv[1]" = get
+        git-get $argv[2..-1]
+    else
+        command git $argv
+    end
+end
+`
+
+const pwshWrapper = `
+function git-get {
+    $exe = Get-Command -Name git-get -CommandType Application | Select-Object -First 1
+    if (($args.Count -gt 0 -and $args[0] -in @(%s)) -or $args -contains '--print-only') {
+        & $exe @args
+        return
+    }
+
+    $out = @(& $exe @args)
+    $code = $LASTEXITCODE
+    $out
+    if ($code -eq 0 -and $out.Count -eq 1 -and (Test-Path -LiteralPath $out[0] -PathType Container)) {
+        Set-Location -LiteralPath $out[0]
+    }
+    $global:LASTEXITCODE = $code
+}
+
+function git {
+    $exe = Get-Command -Name git -CommandType Application | Select-Object -First 1
+    if ($args.Count -gt 0 -and $args[0] -eq 'get') {
+        git-get @($args | Select-Object -Skip 1)
+    } else {
+        & $exe @args
+    }
+}
+`
+
+func shellInit(args []string, stdout io.Writer) error {
+	fs := newFlagSet("shell-init")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, shellInitUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected one shell\n\n%s", shellInitUsage)
+	}
+
+	shell, ok := shells[positional[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q: expected bash, zsh, fish or pwsh", positional[0])
+	}
+
+	completion, err := completions.ReadFile(shell.completion)
+	if err != nil {
+		return fmt.Errorf("reading completions: %w", err)
+	}
+	fmt.Fprint(stdout, string(completion))
+	fmt.Fprintf(stdout, shell.wrapper, shell.commands(passthroughCommands))
+	return nil
+}
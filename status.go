@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const statusUsage = `Usage: git-get status [options]
+
+Show the branch, commits ahead of and behind the upstream, uncommitted changes,
+untracked files, stashes and last commit age of every repository in GETPATH.
+
+Options:
+  --filter <glob>  Only show repositories matching the glob, eg: github.com/arbourd
+                   May be given multiple times
+  --dirty          Only show repositories with uncommitted changes or untracked files
+  --unpushed       Only show repositories with commits that are not on any remote
+  --json           Print JSON
+  -j, --jobs <n>   Read n repositories at a time (default 8)
+  -h, --help       Show this help message`
+
+// statusEntry is a repository and its report as printed by status --json
+type statusEntry struct {
+	get.Repository
+	get.Report
+}
+
+func status(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("status")
+	var filters stringList
+	fs.Var(&filters, "filter", "")
+	dirty := fs.Bool("dirty", false, "")
+	unpushed := fs.Bool("unpushed", false, "")
+	asJSON := fs.Bool("json", false, "")
+	jobs := jobsFlag(fs)
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, statusUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid jobs: %d", *jobs)
+	}
+
+	repos, err := get.List(filters...)
+	if err != nil {
+		return fmt.Errorf("listing repositories: %w", err)
+	}
+
+	reports := make([]get.Report, len(repos))
+	errs := make([]error, len(repos))
+	parallel(*jobs, len(repos), func(i int) {
+		reports[i], errs[i] = get.RepositoryReport(repos[i].Path)
+	})
+
+	// A repository that cannot be read is reported without hiding the others
+	entries := make([]statusEntry, 0, len(repos))
+	var failed int
+	for i, r := range repos {
+		if errs[i] != nil {
+			failed++
+			fmt.Fprintf(stderr, "failed  %s: %s\n", r.Name, errs[i])
+			continue
+		}
+		if *dirty && !reports[i].Dirty() || *unpushed && !reports[i].HasUnpushed() {
+			continue
+		}
+		entries = append(entries, statusEntry{Repository: r, Report: reports[i]})
+	}
+
+	if err := printStatus(stdout, entries, *asJSON); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories could not be read", failed, len(repos))
+	}
+	return nil
+}
+
+// printStatus prints the entries as a table, or as JSON
+func printStatus(w io.Writer, entries []statusEntry, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tBRANCH\tAHEAD\tBEHIND\tCHANGED\tUNTRACKED\tSTASHES\tLAST COMMIT")
+	for _, e := range entries {
+		branch := e.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		ahead, behind := strconv.Itoa(e.Ahead), strconv.Itoa(e.Behind)
+		if e.Upstream == "" {
+			ahead, behind = "-", "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			e.Name, branch, ahead, behind, e.Changed, e.Untracked, e.Stashes, age(now, e.LastCommit))
+	}
+	return tw.Flush()
+}
+
+// age formats the time elapsed since t in its largest whole unit, eg: 3d
+func age(now, t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo", int(d.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arbourd/git-get/get"
+)
+
+const updateUsage = `Usage: git-get update [options]
+
+Fetch every repository in GETPATH.
+
+Options:
+  --filter <glob>  Only update repositories matching the glob, eg: github.com/arbourd
+                   May be given multiple times
+  --pull           Fast-forward the current branch instead of only fetching
+  -j, --jobs <n>   Update n repositories at a time (default 8)
+  --timeout <d>    Stop updating a repository after d, eg: 30s or 2m
+  -h, --help       Show this help message`
+
+// defaultJobs is the number of repositories processed concurrently by default
+const defaultJobs = 8
+
+func update(args []string, stdout io.Writer) error {
+	fs := newFlagSet("update")
+	var filters stringList
+	fs.Var(&filters, "filter", "")
+	pull := fs.Bool("pull", false, "")
+	jobs := jobsFlag(fs)
+	timeout := fs.Duration("timeout", 0, "")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(stdout, updateUsage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("invalid jobs: %d", *jobs)
+	}
+	if *timeout < 0 {
+		return fmt.Errorf("invalid timeout: %s", *timeout)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	repos, err := get.List(filters...)
+	if err != nil {
+		return fmt.Errorf("listing repositories: %w", err)
+	}
+
+	var mu sync.Mutex
+	var failed int
+	parallel(*jobs, len(repos), func(i int) {
+		r := repos[i]
+		ctx, cancel := withTimeout(ctx, *timeout)
+		defer cancel()
+		err := get.UpdateContext(ctx, r.Path, get.UpdateOptions{Pull: *pull})
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "failed  %s: %s\n", r.Name, err)
+			return
+		}
+		fmt.Fprintf(stdout, "ok      %s\n", r.Name)
+	})
+
+	fmt.Fprintf(stdout, "%d updated, %d failed\n", len(repos)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed to update", failed, len(repos))
+	}
+	return nil
+}
+
+// jobsFlag defines the -j and --jobs flags on fs
+func jobsFlag(fs *flag.FlagSet) *int {
+	jobs := fs.Int("jobs", defaultJobs, "")
+	fs.IntVar(jobs, "j", defaultJobs, "")
+	return jobs
+}
+
+// withTimeout returns a context that is canceled after timeout, or ctx if timeout is zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("timed out after %s: %w", timeout, context.DeadlineExceeded))
+}
+
+// parallel calls fn for each index in [0, n) using at most jobs goroutines at a time
+func parallel(jobs, n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, n) {
+		wg.Go(func() {
+			for i := range indexes {
+				fn(i)
+			}
+		})
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}